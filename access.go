@@ -0,0 +1,46 @@
+package cachemem
+
+import "sync"
+
+// accessBuffer collects the keys of records read from a size-bounded cache,
+// so that they can be marked as recently used in batches rather than taking
+// the cache's write lock on every read.
+type accessBuffer[K comparable] struct {
+	mutex sync.Mutex
+	keys  []K
+}
+
+func newAccessBuffer[K comparable](size int) *accessBuffer[K] {
+	if size <= 0 {
+		return nil
+	}
+	return &accessBuffer[K]{keys: make([]K, 0, size)}
+}
+
+// add records an access to key. Once the buffer is full, it returns the
+// buffered keys, oldest first, and starts a new buffer.
+func (b *accessBuffer[K]) add(key K) []K {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.keys = append(b.keys, key)
+	if len(b.keys) < cap(b.keys) {
+		return nil
+	}
+	full := b.keys
+	b.keys = make([]K, 0, cap(full))
+	return full
+}
+
+// touchBuffered marks the records with the given keys as recently used, in
+// order, skipping any no longer in the cache.
+func (cache *Cache[K, V]) touchBuffered(keys []K) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, key := range keys {
+		if _, ok := cache.store[key]; ok {
+			cache.recency.touch(key)
+		}
+	}
+}
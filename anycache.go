@@ -0,0 +1,89 @@
+package cachemem
+
+import (
+	"fmt"
+	"time"
+)
+
+// AnyCache is an untyped view of a Cache, for use by code that cannot be
+// generic over the cache's key and value types. Keys and values of the wrong
+// type are rejected with an error wrapping ErrWrongType.
+type AnyCache interface {
+	Get(key any) (any, bool, error)
+	GetOrFetch(key any, expiresIn time.Duration) (any, error)
+	Set(value any, expiresIn time.Duration) error
+	Delete(key any) error
+	Clear()
+	Len() int
+}
+
+// AsAny returns an untyped view of cache.
+func AsAny[K comparable, V any](cache *Cache[K, V]) AnyCache {
+	return anyCache[K, V]{cache: cache}
+}
+
+type anyCache[K comparable, V any] struct {
+	cache *Cache[K, V]
+}
+
+func assertType[T any](x any) (T, error) {
+	t, ok := x.(T)
+	if !ok {
+		return t, fmt.Errorf("%w: got %T, want %T", ErrWrongType, x, t)
+	}
+	return t, nil
+}
+
+func (c anyCache[K, V]) Get(key any) (any, bool, error) {
+	k, err := assertType[K](key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, ok := c.cache.Get(k)
+	if !ok {
+		return nil, false, nil
+	}
+	return value, true, nil
+}
+
+func (c anyCache[K, V]) GetOrFetch(key any, expiresIn time.Duration) (any, error) {
+	k, err := assertType[K](key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := c.cache.GetOrFetch(k, expiresIn)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (c anyCache[K, V]) Set(value any, expiresIn time.Duration) error {
+	v, err := assertType[V](value)
+	if err != nil {
+		return err
+	}
+
+	c.cache.Set(v, expiresIn)
+	return nil
+}
+
+func (c anyCache[K, V]) Delete(key any) error {
+	k, err := assertType[K](key)
+	if err != nil {
+		return err
+	}
+
+	c.cache.Delete(k)
+	return nil
+}
+
+func (c anyCache[K, V]) Clear() {
+	c.cache.Clear()
+}
+
+func (c anyCache[K, V]) Len() int {
+	return c.cache.Len()
+}
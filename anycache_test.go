@@ -0,0 +1,66 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsAny_Get(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	anyCache := AsAny(cache)
+
+	actual, ok, err := anyCache.Get(1)
+	_, okMissing, errMissing := anyCache.Get(2)
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", actual)
+	assert.NoError(t, errMissing)
+	assert.False(t, okMissing)
+}
+
+func TestAsAny_Get_wrongType(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	anyCache := AsAny(cache)
+
+	_, _, err := anyCache.Get("1")
+	assert.ErrorIs(t, err, ErrWrongType)
+}
+
+func TestAsAny_Set(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	anyCache := AsAny(cache)
+
+	err := anyCache.Set("1", time.Hour)
+	errWrongType := anyCache.Set(1, time.Hour)
+	actual, _ := cache.Get(1)
+
+	assert.NoError(t, err)
+	assert.ErrorIs(t, errWrongType, ErrWrongType)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, 1, anyCache.Len())
+}
+
+func TestAsAny_GetOrFetch(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	anyCache := AsAny(cache)
+
+	actual, err := anyCache.GetOrFetch(2, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2", actual)
+}
+
+func TestAsAny_Delete(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	anyCache := AsAny(cache)
+
+	err := anyCache.Delete(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cache.Len())
+}
@@ -0,0 +1,71 @@
+package cachemem
+
+// Audit re-fetches a sample of at most n cached records and compares them
+// with the cached values using the function configured WithEqual. Size-bounded
+// caches sample their most recently used records; other caches sample
+// arbitrary records. It returns the keys of the sampled records that were
+// stale, and counts the sampled and stale records in Stats, giving evidence
+// of how stale records get within their TTL. Audit is intended to be called
+// periodically, such as from a time.Ticker loop.
+func (cache *Cache[K, V]) Audit(n int) ([]K, error) {
+	if cache.closed.Load() {
+		return nil, ErrClosed
+	}
+	if cache.equal == nil {
+		return nil, ErrNoEqual
+	}
+
+	cached := map[K]V{}
+	var keys []K
+	sample := func(key K, e entry[V], now int64) {
+		if e.hasExpired(now) || e.err != nil {
+			return
+		}
+		cached[key] = e.value
+		keys = append(keys, key)
+	}
+
+	cache.mutex.RLock()
+	now := cache.now()
+	if cache.recency != nil {
+		for element := cache.recency.order.Front(); element != nil && len(keys) < n; element = element.Next() {
+			key := element.Value.(K)
+			sample(key, cache.store[key], now)
+		}
+	} else {
+		for key, e := range cache.store {
+			if len(keys) >= n {
+				break
+			}
+			sample(key, e, now)
+		}
+	}
+	cache.mutex.RUnlock()
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, _, err := cache.fetchMany(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchedKeys, values := cache.keysOf(values)
+	fetched := make(map[K]V, len(values))
+	for i, value := range values {
+		fetched[fetchedKeys[i]] = value
+	}
+
+	var stale []K
+	for _, key := range keys {
+		value, ok := fetched[key]
+		if !ok || !cache.equal(cached[key], value) {
+			stale = append(stale, key)
+		}
+	}
+
+	cache.stats.audited.Add(uint64(len(keys)))
+	cache.stats.auditedStale.Add(uint64(len(stale)))
+	return stale, nil
+}
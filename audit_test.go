@@ -0,0 +1,59 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Audit(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithEqual[int, string](equalStrings))
+	cache.Set("1", time.Hour)
+	cache.Set("02", time.Hour)
+
+	stale, err := cache.Audit(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, stale)
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Audited)
+	assert.Equal(t, uint64(1), stats.AuditedStale)
+	assert.Equal(t, 0.5, stats.StalenessDivergence())
+}
+
+func TestCache_Audit_sampleSize(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithEqual[int, string](equalStrings))
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Set("3", time.Hour)
+
+	stale, err := cache.Audit(2)
+
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+	assert.Len(t, fetcher.FetchManyCalls[0], 2)
+}
+
+func TestCache_Audit_mostRecentlyUsed(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithEqual[int, string](equalStrings), WithMaxEntries[int, string](10))
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Set("3", time.Hour)
+	cache.Get(1)
+
+	_, err := cache.Audit(2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, fetcher.FetchManyCalls[0])
+}
+
+func TestCache_Audit_noEqual(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	_, err := cache.Audit(10)
+	assert.ErrorIs(t, err, ErrNoEqual)
+}
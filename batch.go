@@ -0,0 +1,96 @@
+package cachemem
+
+import (
+	"sync"
+	"time"
+)
+
+// batcher collects the keys of concurrent fetches over a window of time, so
+// that they can be fetched with a single call to FetchMany.
+type batcher[K comparable, V any] struct {
+	mutex   sync.Mutex
+	window  time.Duration
+	pending *batch[K, V]
+}
+
+// batch is a set of keys fetched together. values and err are set before
+// done is closed.
+type batch[K comparable, V any] struct {
+	keys    []K
+	seen    map[K]struct{}
+	done    chan struct{}
+	fetched fetchedBatch[K, V]
+	values  map[K]V
+	err     error
+}
+
+func newBatcher[K comparable, V any](window time.Duration) *batcher[K, V] {
+	if window <= 0 {
+		return nil
+	}
+	return &batcher[K, V]{window: window}
+}
+
+// add adds key to the pending batch, starting a new batch fetched with fetch
+// once the window elapses if there is none.
+func (b *batcher[K, V]) add(key K, fetch func(*batch[K, V])) *batch[K, V] {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.pending == nil {
+		pending := &batch[K, V]{
+			seen: map[K]struct{}{},
+			done: make(chan struct{}),
+		}
+		b.pending = pending
+		time.AfterFunc(b.window, func() {
+			b.mutex.Lock()
+			b.pending = nil
+			b.mutex.Unlock()
+			fetch(pending)
+		})
+	}
+	if _, ok := b.pending.seen[key]; !ok {
+		b.pending.seen[key] = struct{}{}
+		b.pending.keys = append(b.pending.keys, key)
+	}
+	return b.pending
+}
+
+// load fetches the record with key key, batching it with concurrent fetches
+// if the cache was initialized WithBatchWindow.
+func (cache *Cache[K, V]) load(key K) (V, time.Duration, error) {
+	if cache.batcher == nil {
+		return cache.fetchOne(key)
+	}
+
+	b := cache.batcher.add(key, cache.fetchBatch)
+	<-b.done
+
+	var v V
+	if b.err != nil {
+		return v, 0, b.err
+	}
+	v, ok := b.values[key]
+	if !ok {
+		return v, 0, ErrNotFound
+	}
+	return v, b.fetched.expiries[key], nil
+}
+
+// fetchBatch fetches the keys in b, and signals the callers waiting on them.
+func (cache *Cache[K, V]) fetchBatch(b *batch[K, V]) {
+	defer close(b.done)
+
+	fetched, err := cache.fetchKeyed(b.keys)
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	b.fetched = fetched
+	b.values = make(map[K]V, len(fetched.values))
+	for i, key := range fetched.keys {
+		b.values[key] = fetched.values[i]
+	}
+}
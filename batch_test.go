@@ -0,0 +1,39 @@
+package cachemem
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_WithBatchWindow(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithBatchWindow[int, string](10*time.Millisecond))
+
+	var wg sync.WaitGroup
+	actual := make([]string, 3)
+	for i := range actual {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actual[i], _ = cache.GetOrFetch(i, time.Hour)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []string{"0", "1", "2"}, actual)
+	require.Len(t, fetcher.FetchManyCalls, 1)
+	assert.ElementsMatch(t, []int{0, 1, 2}, fetcher.FetchManyCalls[0])
+	assert.Equal(t, 3, cache.Len())
+}
+
+func TestCache_WithBatchWindow_notFetched(t *testing.T) {
+	cache, _ := New[int, string](zeroFetcher{}, fixedKey, WithBatchWindow[int, string](time.Millisecond))
+
+	_, err := cache.GetOrFetch(2, time.Hour)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+}
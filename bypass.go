@@ -0,0 +1,37 @@
+package cachemem
+
+import (
+	"math/rand"
+	"time"
+)
+
+// shouldBypass reports whether a GetOrFetch call should go straight to the
+// fetcher.
+func (cache *Cache[K, V]) shouldBypass() bool {
+	return cache.bypassFraction > 0 && rand.Float64() < cache.bypassFraction
+}
+
+// bypass fetches and caches the record with key key regardless of whether
+// it is cached, recording what the cache would have returned instead.
+func (cache *Cache[K, V]) bypass(key K, expiresIn time.Duration) (V, error) {
+	cache.stats.bypasses.Add(1)
+
+	cache.mutex.RLock()
+	e, exists := cache.store[key]
+	cache.mutex.RUnlock()
+	wouldHit := exists && !e.hasExpired(cache.now()) && e.err == nil
+
+	fetchedValue, err := cache.fetch(key, expiresIn)
+	if err != nil {
+		return fetchedValue, err
+	}
+
+	if wouldHit {
+		cache.stats.bypassHits.Add(1)
+		if cache.equal != nil && !cache.equal(e.value, fetchedValue) {
+			cache.stats.bypassMismatches.Add(1)
+		}
+	}
+
+	return fetchedValue, nil
+}
@@ -0,0 +1,43 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func equalStrings(a, b string) bool {
+	return a == b
+}
+
+func TestCache_WithBypassFraction(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		func(s string) int { return 1 },
+		WithBypassFraction[int, string](1),
+		WithEqual[int, string](equalStrings),
+	)
+	cache.Set("stale", time.Hour)
+
+	actual, err := cache.GetOrFetch(1, time.Hour)
+	cachedValue, _ := cache.Get(1)
+	_, _ = cache.GetOrFetch(2, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, "1", cachedValue)
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Bypasses)
+	assert.Equal(t, uint64(1), stats.BypassHits)
+	assert.Equal(t, uint64(1), stats.BypassMismatches)
+}
+
+func TestCache_WithBypassFraction_zero(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithBypassFraction[int, string](0))
+	cache.Set("1", time.Hour)
+
+	_, _ = cache.GetOrFetch(1, time.Hour)
+
+	assert.Equal(t, uint64(0), cache.Stats().Bypasses)
+}
@@ -1,7 +1,12 @@
 package cachemem
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,182 +16,990 @@ type Fetcher[K comparable, V any] interface {
 	FetchMany(arrK []K) ([]V, error)
 }
 
+// entry is a cached record. expiresAt is stored as nanoseconds since the
+// cache's epoch rather than as a time.Time, which keeps entries 16 bytes
+// smaller and free of pointers for the garbage collector to scan. ttl is the
+// expiry duration the record was written with, if known. Records with an idle
+// timeout also store it, idle, and the deadline past which reads no longer
+// extend their expiry, limit. version identifies the write that stored the
+// entry.
 type entry[V any] struct {
 	value     V
-	expiresAt time.Time
+	err       error
+	expiresAt int64
+	cost      int64
+	ttl       int64
+	idle      int64
+	limit     int64
+	version   uint64
 }
 
-func (e *entry[V]) hasExpired() bool {
-	return time.Now().After(e.expiresAt)
+func (e *entry[V]) hasExpired(now int64) bool {
+	return !e.permanent() && now >= e.expiresAt
 }
 
-// Cache is a strongly typed, concurrency-safe, in-memory cache.
+// permanent reports whether the entry never expires.
+func (e *entry[V]) permanent() bool {
+	return e.expiresAt == math.MaxInt64
+}
+
+// NoExpiry is an expiry duration for records that never expire. Durations
+// too long to represent from now on are treated the same way.
+const NoExpiry time.Duration = math.MaxInt64
+
+// Cache is a strongly typed, concurrency-safe, in-memory cache. Caches are
+// created by New and must not be copied; go vet reports copies.
 type Cache[K comparable, V any] struct {
-	fetcher         Fetcher[K, V]
-	getKey          func(V) K
-	mutex           sync.Mutex
-	store           map[K]entry[V]
-	cleanFreq       time.Duration
-	signalStopClean chan struct{}
-	isCleaning      bool
+	fetcher            Fetcher[K, V]
+	getKey             func(V) K
+	mutex              sync.RWMutex
+	epoch              time.Time
+	store              map[K]entry[V]
+	initialCapacity    int
+	peak               int
+	maxEntries         int
+	maxCost            int64
+	cost               int64
+	costs              costHistogram
+	permanent          int
+	version            uint64
+	weigher            func(V) int64
+	recency            *lru[K]
+	bounded            bool
+	admission          Admission[K]
+	onEvict            func(K, V)
+	onExpire           func(K, V)
+	expiredQueue       chan ExpiredEntry[K, V]
+	expiredMutex       sync.Mutex
+	cleanFreq          time.Duration
+	cleanMutex         sync.Mutex
+	stopClean          chan struct{}
+	cleanDone          chan struct{}
+	lastClean          time.Time
+	lastCleaned        int
+	quiescence         *quiescence
+	dependents         map[K]map[K]struct{}
+	dependencies       map[K][]K
+	derivations        map[K]derivation[K, V]
+	index              keyIndex[K]
+	history            *history[K]
+	shadow             *shadow[K]
+	uniqueKeys         *hyperLogLog[K]
+	bypassFraction     float64
+	equal              func(a, b V) bool
+	validationFraction float64
+	negativeTTL        func(error) time.Duration
+	ttlOverrides       map[K]time.Duration
+	expiryFunc         func(V) (time.Time, bool)
+	readThroughTTL     time.Duration
+	keyMismatch        KeyMismatchPolicy
+	strictFetchMany    bool
+	zeroValue          ZeroValuePolicy
+	panicHandler       func(*PanicError)
+	batcher            *batcher[K, V]
+	clock              *coarseClock
+	accesses           *accessBuffer[K]
+	defaultTTL         time.Duration
+	prefetcher         *prefetcher[K]
+	ttlJitter          float64
+	sliding            bool
+	maxLifetime        time.Duration
+	idleTTL            time.Duration
+	predictor          *predictor[K]
+	frozen             atomic.Bool
+	closed             atomic.Bool
+	stats              stats
+}
+
+// keyIndex is a secondary index over the keys in the cache, kept up to date
+// under the cache mutex.
+type keyIndex[K comparable] interface {
+	add(K)
+	remove(K)
+	reset()
+}
+
+// NewStatic initializes a new, empty Cache without a fetcher, for use as a
+// plain concurrent TTL cache. GetOrFetch and FetchMany return ErrNoFetcher
+// for records missing from it.
+func NewStatic[K comparable, V any](getKey func(V) K, opts ...Option[K, V]) (*Cache[K, V], error) {
+	return New[K, V](nil, getKey, opts...)
 }
 
-// New initializes a new, empty Cache.
-func New[K comparable, V any](fetcher Fetcher[K, V], getKey func(V) K, cleanFreq time.Duration) Cache[K, V] {
-	return Cache[K, V]{
-		fetcher:         fetcher,
-		getKey:          getKey,
-		mutex:           sync.Mutex{},
-		store:           map[K]entry[V]{},
-		cleanFreq:       cleanFreq,
-		signalStopClean: make(chan struct{}),
-		isCleaning:      false,
+// New initializes a new, empty Cache configured by opts. It returns an error
+// wrapping ErrInvalidConfig if the options are invalid.
+func New[K comparable, V any](fetcher Fetcher[K, V], getKey func(V) K, opts ...Option[K, V]) (*Cache[K, V], error) {
+	cfg := newConfig(opts)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if fetcher == nil {
+		switch {
+		case cfg.ReadThrough:
+			return nil, fmt.Errorf("%w: read-through requires a fetcher", ErrInvalidConfig)
+		case cfg.BatchWindow > 0:
+			return nil, fmt.Errorf("%w: batching requires a fetcher", ErrInvalidConfig)
+		case cfg.BypassFraction > 0:
+			return nil, fmt.Errorf("%w: bypass requires a fetcher", ErrInvalidConfig)
+		case cfg.ValidationFraction > 0:
+			return nil, fmt.Errorf("%w: validation requires a fetcher", ErrInvalidConfig)
+		case cfg.PredictorCapacity > 0:
+			return nil, fmt.Errorf("%w: predictive prefetch requires a fetcher", ErrInvalidConfig)
+		}
+	}
+
+	cache := &Cache[K, V]{}
+	cache.init(fetcher, getKey, cfg)
+	if cfg.ClockResolution > 0 {
+		cache.startClock(cfg.ClockResolution)
+	}
+	if cfg.Janitor {
+		cache.startJanitor()
+	}
+	return cache, nil
+}
+
+// init initializes cache in place. Caches must not be copied once
+// initialized, so embedding types initialize their Cache with init rather
+// than assigning one.
+func (cache *Cache[K, V]) init(fetcher Fetcher[K, V], getKey func(V) K, cfg Config[K, V]) {
+	if cfg.CleanFrequency == 0 {
+		cfg.CleanFrequency = DefaultCleanFrequency
+	}
+	if cfg.DefaultTTL == 0 {
+		cfg.DefaultTTL = NoExpiry
+	}
+	*cache = Cache[K, V]{
+		fetcher:            fetcher,
+		getKey:             getKey,
+		epoch:              time.Now(),
+		store:              make(map[K]entry[V], cfg.InitialCapacity),
+		initialCapacity:    cfg.InitialCapacity,
+		maxEntries:         cfg.MaxEntries,
+		maxCost:            cfg.MaxCost,
+		weigher:            cfg.Weigher,
+		recency:            newRecency[K](cfg.MaxEntries > 0 || cfg.MaxCost > 0),
+		bounded:            cfg.MaxEntries > 0 || cfg.MaxCost > 0,
+		admission:          cfg.Admission,
+		onEvict:            cfg.OnEvict,
+		onExpire:           cfg.OnExpire,
+		expiredQueue:       newExpiredQueue[K, V](cfg.ExpiredQueue),
+		cleanFreq:          cfg.CleanFrequency,
+		quiescence:         newQuiescence(cfg.Quiescence),
+		dependents:         map[K]map[K]struct{}{},
+		dependencies:       map[K][]K{},
+		derivations:        map[K]derivation[K, V]{},
+		ttlOverrides:       map[K]time.Duration{},
+		index:              cfg.index,
+		history:            newHistory[K](cfg.HistorySize),
+		shadow:             newShadow[K](cfg.ShadowCapacity),
+		uniqueKeys:         newHyperLogLog[K](cfg.UniqueKeys),
+		bypassFraction:     cfg.BypassFraction,
+		equal:              cfg.Equal,
+		validationFraction: cfg.ValidationFraction,
+		negativeTTL:        cfg.NegativeTTL,
+		expiryFunc:         cfg.ExpiryFunc,
+		readThroughTTL:     cfg.ReadThroughTTL,
+		keyMismatch:        cfg.KeyMismatch,
+		strictFetchMany:    cfg.StrictFetchMany,
+		zeroValue:          cfg.ZeroValue,
+		panicHandler:       cfg.PanicHandler,
+		accesses:           newAccessBuffer[K](cfg.AccessBuffer),
+		defaultTTL:         cfg.DefaultTTL,
+		prefetcher:         newPrefetcher[K](cfg.PrefetchWorkers),
+		ttlJitter:          cfg.TTLJitter,
+		sliding:            cfg.SlidingExpiration,
+		maxLifetime:        cfg.MaxLifetime,
+		idleTTL:            cfg.IdleTTL,
+		predictor:          newPredictor[K](cfg.PredictorCapacity),
+		batcher:            newBatcher[K, V](cfg.BatchWindow),
 	}
 }
 
 // StartCleaning begins removing expired records from the cache at the configured frequency.
 // It blocks until StopCleaning is called.
 func (cache *Cache[K, V]) StartCleaning() {
-	if cache.isCleaning {
+	cache.StartCleaningContext(context.Background())
+}
+
+// StartCleaningContext is like StartCleaning, but also stops cleaning when
+// ctx is done. It returns immediately if the cache is already being cleaned.
+func (cache *Cache[K, V]) StartCleaningContext(ctx context.Context) {
+	stop, done, ok := cache.claimCleaning()
+	if !ok {
 		return
 	}
+	cache.runCleaning(ctx, stop, done)
+}
+
+// startJanitor starts cleaning the cache in a new goroutine. The cache is
+// marked as being cleaned before startJanitor returns, so StopCleaning stops
+// the janitor and StartCleaning returns immediately.
+func (cache *Cache[K, V]) startJanitor() {
+	stop, done, ok := cache.claimCleaning()
+	if ok {
+		go cache.runCleaning(context.Background(), stop, done)
+	}
+}
+
+// claimCleaning marks the cache as being cleaned, returning the channels
+// signalling the cleaner to stop and closed once it has, or false if the
+// cache is already being cleaned or is closed.
+func (cache *Cache[K, V]) claimCleaning() (stop, done chan struct{}, ok bool) {
+	cache.cleanMutex.Lock()
+	defer cache.cleanMutex.Unlock()
+
+	if cache.cleanDone != nil || cache.closed.Load() {
+		return nil, nil, false
+	}
+	stop = make(chan struct{})
+	done = make(chan struct{})
+	cache.stopClean = stop
+	cache.cleanDone = done
+	return stop, done, true
+}
+
+// runCleaning removes expired records at the configured frequency until
+// stop is closed or ctx is done, and then closes done.
+func (cache *Cache[K, V]) runCleaning(ctx context.Context, stop, done chan struct{}) {
+	defer func() {
+		cache.cleanMutex.Lock()
+		cache.stopClean = nil
+		cache.cleanDone = nil
+		cache.cleanMutex.Unlock()
+		close(done)
+	}()
 
-	cache.isCleaning = true
 	ticker := time.NewTicker(cache.cleanFreq)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
-			cache.clean()
+			if cache.quiescent() {
+				ticker.Stop()
+				if !cache.awaitActivity(ctx, stop) {
+					return
+				}
+				ticker.Reset(cache.cleanFreq)
+				continue
+			}
+			removed := cache.clean()
+			cache.cleanMutex.Lock()
+			cache.lastClean = time.Now()
+			cache.lastCleaned = removed
+			cache.cleanMutex.Unlock()
+
+		case <-stop:
+			return
 
-		case <-cache.signalStopClean:
-			ticker.Stop()
-			cache.isCleaning = false
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// StopCleaning stops removing expired records from the cache.
+// StopCleaning stops removing expired records from the cache. It does nothing
+// if the cache is not being cleaned, and does not wait for a clean in
+// progress to finish.
 func (cache *Cache[K, V]) StopCleaning() {
-	if !cache.isCleaning {
-		return
+	cache.stopCleaning()
+}
+
+// StopCleaningContext is like StopCleaning, but waits for the cleaner to
+// stop, returning ctx.Err() if ctx is done first.
+func (cache *Cache[K, V]) StopCleaningContext(ctx context.Context) error {
+	done := cache.stopCleaning()
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	cache.signalStopClean <- struct{}{}
 }
 
-func (cache *Cache[K, V]) clean() {
+// stopCleaning signals the cleaner to stop, returning a channel closed once
+// it has, or nil if the cache is not being cleaned.
+func (cache *Cache[K, V]) stopCleaning() <-chan struct{} {
+	cache.cleanMutex.Lock()
+	defer cache.cleanMutex.Unlock()
+
+	if cache.stopClean != nil {
+		close(cache.stopClean)
+		cache.stopClean = nil
+	}
+	return cache.cleanDone
+}
+
+// CleaningStatus describes the cleaner removing expired records from a
+// cache.
+type CleaningStatus struct {
+	// Running reports whether the cache is being cleaned.
+	Running bool
+	// Paused reports whether cleaning is paused because the cache has not
+	// been used recently, see WithQuiescence.
+	Paused bool
+	// LastPass is when the cleaner last removed expired records, or the zero
+	// time if it never has.
+	LastPass time.Time
+	// LastRemoved is the number of records removed by the last pass.
+	LastRemoved int
+	// Removed is the total number of records removed by the cleaner, as
+	// reported by Stats().Expired.
+	Removed uint64
+}
+
+// CleaningStatus returns the status of the cache's cleaner.
+func (cache *Cache[K, V]) CleaningStatus() CleaningStatus {
+	cache.cleanMutex.Lock()
+	defer cache.cleanMutex.Unlock()
+
+	return CleaningStatus{
+		Running:     cache.cleanDone != nil,
+		Paused:      cache.quiescence != nil && cache.quiescence.paused.Load(),
+		LastPass:    cache.lastClean,
+		LastRemoved: cache.lastCleaned,
+		Removed:     cache.stats.expired.Load(),
+	}
+}
+
+// now returns the current time as nanoseconds since the cache's epoch. The
+// epoch carries a monotonic clock reading, so expiry is unaffected by wall
+// clock jumps.
+func (cache *Cache[K, V]) now() int64 {
+	if cache.clock != nil {
+		return cache.clock.now.Load()
+	}
+	return int64(time.Since(cache.epoch))
+}
+
+// expiry returns the deadline expiresIn from now, less any jitter,
+// saturating rather than overflowing for very long durations.
+func (cache *Cache[K, V]) expiry(expiresIn time.Duration) int64 {
+	now := cache.now()
+	if cache.ttlJitter > 0 && expiresIn != NoExpiry {
+		expiresIn -= time.Duration(float64(expiresIn) * cache.ttlJitter * rand.Float64())
+	}
+	if int64(expiresIn) > math.MaxInt64-now {
+		return math.MaxInt64
+	}
+	return now + int64(expiresIn)
+}
+
+// deadline converts t to nanoseconds since the cache's epoch.
+func (cache *Cache[K, V]) deadline(t time.Time) int64 {
+	return int64(t.Sub(cache.epoch))
+}
+
+// clean removes expired records from the cache, returning how many it
+// removed, and then passes them to the expiry callback and queue, if any.
+func (cache *Cache[K, V]) clean() int {
+	var expired []ExpiredEntry[K, V]
+	notify := cache.onExpire != nil || cache.expiredQueue != nil
+
+	cache.mutex.Lock()
+	removed := 0
+	now := cache.now()
 	for k, v := range cache.store {
-		if v.hasExpired() {
-			cache.Delete(k)
+		if v.hasExpired(now) {
+			cache.deleteLocked(k, ReasonExpired)
+			cache.stats.expired.Add(1)
+			removed++
+			if notify && v.err == nil {
+				expired = append(expired, ExpiredEntry[K, V]{
+					Key:       k,
+					Value:     v.value,
+					ExpiredAt: cache.epoch.Add(time.Duration(v.expiresAt)),
+				})
+			}
 		}
 	}
+	cache.compactLocked()
+	cache.mutex.Unlock()
+
+	cache.notifyExpired(expired)
+	return removed
 }
 
-func (cache *Cache[K, V]) set(e entry[V]) {
+func (cache *Cache[K, V]) set(value V, expiresIn time.Duration, deps []K, reason string) {
+	key, err := cache.keyOf(value)
+	if err != nil {
+		return
+	}
 	cache.mutex.Lock()
-	cache.store[cache.getKey(e.value)] = e
-	cache.mutex.Unlock()
+	defer cache.mutex.Unlock()
+	cache.putLocked(key, value, expiresIn, deps, reason)
+}
+
+// putLocked stores value under key with expiry duration expiresIn, or the
+// TTL override for key if there is one, and the cache's idle timeout, if
+// any. The caller must hold the mutex.
+func (cache *Cache[K, V]) putLocked(key K, value V, expiresIn time.Duration, deps []K, reason string) {
+	if ttl, ok := cache.ttlOverrides[key]; ok {
+		expiresIn = ttl
+	}
+
+	switch {
+	case cache.idleTTL > 0:
+		cache.storeLocked(key, value, cache.idleTTL, expiresIn, deps, reason)
+	case cache.sliding && expiresIn > 0 && expiresIn != NoExpiry:
+		cache.storeLocked(key, value, expiresIn, cache.maxLifetime, deps, reason)
+	default:
+		cache.storeLocked(key, value, 0, expiresIn, deps, reason)
+	}
+}
+
+// storeLocked stores value under key, expiring after idle without being
+// read, unless idle is zero or NoExpiry, and after absolute regardless. A
+// record with a non-positive absolute expiry duration expires immediately, so
+// it is not stored and replaces any existing record with the same key. The
+// caller must hold the mutex.
+func (cache *Cache[K, V]) storeLocked(key K, value V, idle, absolute time.Duration, deps []K, reason string) {
+	if absolute <= 0 {
+		if cache.frozen.Load() {
+			cache.stats.rejectedWrites.Add(1)
+			return
+		}
+		cache.deleteLocked(key, ReasonExpired)
+		return
+	}
+
+	e := entry[V]{
+		value: value,
+		ttl:   int64(absolute),
+	}
+	if idle > 0 && idle != NoExpiry {
+		e.idle = int64(idle)
+	}
+	cache.resetExpiry(&e)
+	cache.setLocked(key, e, deps, reason)
+}
+
+// resetExpiry sets the expiry of e as if it had just been written with its
+// expiry duration and idle timeout.
+func (cache *Cache[K, V]) resetExpiry(e *entry[V]) {
+	e.expiresAt = cache.expiry(time.Duration(e.ttl))
+	if e.idle > 0 {
+		e.limit = e.expiresAt
+		e.expiresAt = min(cache.expiry(time.Duration(e.idle)), e.limit)
+	}
+}
+
+// setLocked stores e under key, replacing any previous entry and its declared
+// dependencies, and invalidates the entries that depend on key. The write is
+// dropped if the cache is frozen. The caller must hold the mutex.
+func (cache *Cache[K, V]) setLocked(key K, e entry[V], deps []K, reason string) {
+	if cache.closed.Load() {
+		return
+	}
+	cache.active()
+	if cache.frozen.Load() {
+		cache.stats.rejectedWrites.Add(1)
+		return
+	}
+
+	cache.capToDepsLocked(&e, deps)
+	if e.err == nil {
+		e.cost = cache.weigh(e.value)
+	}
+	if cache.maxCost > 0 && e.cost > cache.maxCost {
+		// the record could never fit, so it is evicted straight away
+		cache.deleteLocked(key, ReasonEvicted)
+		cache.stats.evicted.Add(1)
+		cache.evicted(key, e.value)
+		return
+	}
+
+	cache.unlink(key)
+	if previous, ok := cache.store[key]; ok {
+		cache.cost -= previous.cost
+		cache.costs.remove(previous.cost)
+		if previous.permanent() {
+			cache.permanent--
+		}
+	} else {
+		cache.stats.added.Add(1)
+	}
+	cache.cost += e.cost
+	cache.costs.add(e.cost)
+	if e.permanent() {
+		cache.permanent++
+	}
+	cache.uniqueKeys.add(key)
+	cache.version++
+	e.version = cache.version
+	cache.store[key] = e
+	cache.peak = max(cache.peak, len(cache.store))
+	cache.history.record(key, OpSet, reason)
+	if cache.index != nil {
+		cache.index.add(key)
+	}
+	cache.touchLocked(key)
+	cache.invalidateDependents(key)
+	cache.link(key, deps)
+	cache.evictLocked()
+}
+
+// deleteLocked removes key from the cache along with every entry that
+// transitively depends on it. The caller must hold the mutex.
+func (cache *Cache[K, V]) deleteLocked(key K, reason string) {
+	cache.unlink(key)
+	if e, ok := cache.store[key]; ok {
+		delete(cache.store, key)
+		cache.cost -= e.cost
+		cache.costs.remove(e.cost)
+		if e.permanent() {
+			cache.permanent--
+		}
+		cache.history.record(key, OpDelete, reason)
+	}
+	if cache.recency != nil {
+		cache.recency.remove(key)
+	}
+	if cache.index != nil {
+		cache.index.remove(key)
+	}
+	cache.invalidateDependents(key)
 }
 
 // Get retrieves a record with key Key from the cache if it exists and
-// has not expired.
+// has not expired. Missing records registered with Derive are recomputed,
+// and other missing records are fetched if the cache was initialized
+// WithReadThrough.
 func (cache *Cache[K, V]) Get(key K) (V, bool) {
+	e, ok := cache.get(key)
+	if !ok && cache.readThroughTTL > 0 {
+		value, err := cache.fetch(key, cache.readThroughTTL)
+		return value, err == nil
+	}
+	return e.value, ok && e.err == nil
+}
+
+// Peek retrieves a record with key key from the cache if it exists and has
+// not expired, like Get, but without side effects: it is not counted in
+// Stats, does not mark the record as recently used or extend its sliding
+// expiration, and does not recompute or fetch missing records.
+func (cache *Cache[K, V]) Peek(key K) (V, bool) {
+	e, ok := cache.lookup(key)
+	return e.value, ok
+}
+
+// Has reports whether a record with key key exists and has not expired,
+// without copying its value. Like Peek, it has no side effects.
+func (cache *Cache[K, V]) Has(key K) bool {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	if _, ok := cache.store[key]; !ok {
+		return false
+	}
+	// selecting fields of the map element avoids copying the whole entry
+	expiresAt, err := cache.store[key].expiresAt, cache.store[key].err
+	return err == nil && (expiresAt == math.MaxInt64 || cache.now() < expiresAt)
+}
+
+// SetForever writes a new entry to the cache like Set, which never expires.
+func (cache *Cache[K, V]) SetForever(value V) {
+	cache.Set(value, NoExpiry)
+}
+
+// GetE is like Get, but reports why a record could not be retrieved. It
+// returns ErrNotFound if the record is not in the cache, or the fetch error
+// if the cache was initialized WithReadThrough and fetching it failed. Fetch
+// errors cached WithNegativeTTL are returned as well.
+func (cache *Cache[K, V]) GetE(key K) (V, error) {
+	if cache.closed.Load() {
+		var v V
+		return v, ErrClosed
+	}
+
+	e, ok := cache.get(key)
+	if ok {
+		return e.value, e.err
+	}
+	if cache.readThroughTTL > 0 {
+		return cache.fetch(key, cache.readThroughTTL)
+	}
+	var v V
+	return v, ErrNotFound
+}
+
+// get retrieves the entry with key key if it exists and has not expired,
+// recomputing derived entries, and records the read. The entry may hold a
+// cached fetch error.
+func (cache *Cache[K, V]) get(key K) (entry[V], bool) {
+	cache.active()
+	cache.mutex.RLock()
 	e, exists := cache.store[key]
-	if !exists || e.hasExpired() {
-		return e.value, false
+	cache.mutex.RUnlock()
+	if !exists || e.hasExpired(cache.now()) {
+		if derived, err := cache.recompute(key); err == nil {
+			cache.recordRead(key, readHit)
+			return derived, true
+		}
+		cache.recordRead(key, readMiss)
+		return e, false
 	}
 
-	return e.value, true
+	cache.touch(key)
+	if e.idle > 0 {
+		cache.slide(key)
+	}
+	if e.err != nil {
+		cache.recordRead(key, readNegativeHit)
+	} else {
+		cache.recordRead(key, readHit)
+	}
+	return e, true
 }
 
 // GetMany retrieves the subset of the provided records from the cache that exist and have not expired.
 func (cache *Cache[K, V]) GetMany(keys []K) []V {
 	var cachedRecords []V
 
-	for _, key := range keys {
-		value, ok := cache.Get(key)
-		if ok {
-			cachedRecords = append(cachedRecords, value)
+	entries, found := cache.getMany(keys)
+	for i, e := range entries {
+		if found[i] && e.err == nil {
+			cachedRecords = append(cachedRecords, e.value)
 		}
 	}
 
 	return cachedRecords
 }
 
+// lookupMany looks up the entries with the given keys under a single lock
+// acquisition, without recomputing derived entries or recording the reads.
+func (cache *Cache[K, V]) lookupMany(keys []K) (entries []entry[V], found []bool) {
+	cache.active()
+	entries = make([]entry[V], len(keys))
+	found = make([]bool, len(keys))
+
+	// reads of size-bounded caches update the recency list, so they need the
+	// write lock unless the updates are buffered
+	exclusive := cache.bounded && cache.accesses == nil
+	if exclusive {
+		cache.mutex.Lock()
+	} else {
+		cache.mutex.RLock()
+	}
+	now := cache.now()
+	for i, key := range keys {
+		e, exists := cache.store[key]
+		if exists && !e.hasExpired(now) {
+			entries[i] = e
+			found[i] = true
+			if exclusive {
+				cache.touchLocked(key)
+			}
+		}
+	}
+	if exclusive {
+		cache.mutex.Unlock()
+	} else {
+		cache.mutex.RUnlock()
+	}
+
+	if cache.accesses != nil {
+		for i, key := range keys {
+			if found[i] {
+				cache.touch(key)
+			}
+		}
+	}
+	for i, key := range keys {
+		if found[i] && entries[i].idle > 0 {
+			cache.slide(key)
+		}
+	}
+	return entries, found
+}
+
+// getMany retrieves the entries with the given keys like get, looking them
+// up under a single lock acquisition. found reports which keys were found.
+func (cache *Cache[K, V]) getMany(keys []K) (entries []entry[V], found []bool) {
+	entries, found = cache.lookupMany(keys)
+
+	for i, key := range keys {
+		switch {
+		case !found[i]:
+			// get recomputes derived entries, and records the read
+			entries[i], found[i] = cache.get(key)
+		case entries[i].err != nil:
+			cache.recordRead(key, readNegativeHit)
+		default:
+			cache.recordRead(key, readHit)
+		}
+	}
+
+	return entries, found
+}
+
 // Set writes a new entry to the cache with expiry duration expiresIn.
 // If an entry with the same key already exists, it will be overwritten.
 // After expiresIn has elapsed, the entry will be deleted from the cache.
+// An entry with an expiresIn of zero or less expires immediately, so it only
+// deletes any existing entry with the same key.
 func (cache *Cache[K, V]) Set(value V, expiresIn time.Duration) {
-	e := entry[V]{
-		value:     value,
-		expiresAt: time.Now().Add(expiresIn),
-	}
-	cache.set(e)
+	cache.set(value, expiresIn, nil, "")
 }
 
 // GetOrFetch retrieves a record by key from the cache if it exists and
 // has not expired, otherwise it fetches and caches it with the provided expiry.
+// If the cache was initialized WithNegativeTTL, fetch errors are cached too,
+// and returned until the negative TTL elapses.
 func (cache *Cache[K, V]) GetOrFetch(key K, expiresIn time.Duration) (V, error) {
-	cachedValue, ok := cache.Get(key)
+	if cache.closed.Load() {
+		var v V
+		return v, ErrClosed
+	}
+	if cache.shouldBypass() {
+		return cache.bypass(key, expiresIn)
+	}
+
+	e, ok := cache.get(key)
 	if ok {
-		return cachedValue, nil
+		if e.err == nil && cache.shouldValidate() {
+			cache.validate(key, e.value)
+		}
+		return e.value, e.err
 	}
 
-	fetchedValue, err := cache.fetcher.FetchOne(key)
+	return cache.fetch(key, expiresIn)
+}
+
+// fetch fetches and caches a record by key with the provided expiry.
+func (cache *Cache[K, V]) fetch(key K, expiresIn time.Duration) (V, error) {
+	e, err := cache.fetchEntry(key, expiresIn)
+	return e.value, err
+}
+
+// fetchEntry is like fetch, but returns the entry cached for key, or the
+// fetched value as an already expired entry if it was not cached under key.
+func (cache *Cache[K, V]) fetchEntry(key K, expiresIn time.Duration) (entry[V], error) {
+	if cache.closed.Load() {
+		return entry[V]{}, ErrClosed
+	}
+	if cache.fetcher == nil {
+		return entry[V]{}, ErrNoFetcher
+	}
+	fetchedValue, ttl, err := cache.load(key)
 	if err != nil {
-		var v V
-		return v, err
+		cache.setError(key, err)
+		return entry[V]{}, err
+	}
+	if ttl != 0 {
+		expiresIn = ttl
+	}
+
+	if cache.zeroValue != ZeroValueCache && isZero(fetchedValue) {
+		v, err := cache.setZero(key)
+		return entry[V]{value: v}, err
+	}
+	fetchedKey, err := cache.keyOf(fetchedValue)
+	if err != nil {
+		return entry[V]{}, err
+	}
+	if fetchedKey != key {
+		return cache.setMismatched(key, fetchedKey, fetchedValue, expiresIn)
+	}
+	return cache.setFetched([]K{key}, []V{fetchedValue}, expiresIn, nil)[0], nil
+}
+
+// setError caches err as the result of fetching key, if the cache was
+// initialized with a negative TTL for it.
+func (cache *Cache[K, V]) setError(key K, err error) {
+	if cache.negativeTTL == nil {
+		return
+	}
+	ttl := cache.negativeTTL(err)
+	if ttl <= 0 {
+		return
 	}
 
-	cache.Set(fetchedValue, expiresIn)
-	return fetchedValue, nil
+	e := entry[V]{
+		err:       err,
+		expiresAt: cache.expiry(ttl),
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.setLocked(key, e, nil, ReasonFailed)
 }
 
-// Delete deletes an record by key from the cache.
+// Delete deletes an record by key from the cache, along with any records
+// that depend on it.
 func (cache *Cache[K, V]) Delete(key K) {
 	cache.mutex.Lock()
-	delete(cache.store, key)
-	cache.mutex.Unlock()
+	defer cache.mutex.Unlock()
+	cache.deleteLocked(key, "")
+}
+
+// DeleteMany deletes the records with the given keys, along with any records
+// that depend on them, like Delete but under a single lock acquisition.
+func (cache *Cache[K, V]) DeleteMany(keys []K) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, key := range keys {
+		cache.deleteLocked(key, "")
+	}
+}
+
+// DeleteWhere deletes every record for which match returns true, along with
+// any records that depend on them, under a single lock acquisition, and
+// returns the number of records matched. Cached fetch errors are not passed
+// to match. match is called while holding the cache's lock, so it must not
+// use the cache.
+func (cache *Cache[K, V]) DeleteWhere(match func(K, V) bool) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	var keys []K
+	for key, e := range cache.store {
+		if e.err == nil && match(key, e.value) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		cache.deleteLocked(key, "")
+	}
+	return len(keys)
 }
 
 // Clear deletes all entries in the cache.
 func (cache *Cache[K, V]) Clear() {
 	cache.mutex.Lock()
-	cache.store = map[K]entry[V]{}
+	cache.store = make(map[K]entry[V], cache.initialCapacity)
+	cache.peak = 0
+	cache.recency = newRecency[K](cache.recency != nil)
+	cache.cost = 0
+	cache.costs = costHistogram{}
+	cache.permanent = 0
+	cache.dependents = map[K]map[K]struct{}{}
+	cache.dependencies = map[K][]K{}
+	if cache.index != nil {
+		cache.index.reset()
+	}
+	var zero K
+	cache.history.record(zero, OpClear, "")
 	cache.mutex.Unlock()
 }
 
 // Len returns the number of records in the cache, including
-// expired records.
+// expired records and cached fetch errors. Stats().Permanent reports how many
+// of them never expire.
 func (cache *Cache[K, V]) Len() int {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
 	return len(cache.store)
 }
 
 // FetchMany fetches and caches the subset of the provided records that have
-// not been cached and have not expired.
+// not been cached and have not expired. Each key is fetched at most once. If
+// the fetcher returns several records with the same key, the last one is
+// cached and the others are counted in Stats().DuplicateFetches. Records with
+// keys that were not requested are cached too, unless the cache was
+// initialized WithStrictFetchMany.
 func (cache *Cache[K, V]) FetchMany(arrK []K, expiresIn time.Duration) error {
-	expiresAt := time.Now().Add(expiresIn)
+	if cache.closed.Load() {
+		return ErrClosed
+	}
+	_, found := cache.getMany(arrK)
+	return cache.fetchMissing(arrK, found, expiresIn)
+}
 
+// fetchMissing fetches and caches the records with the given keys that were
+// not found, like FetchMany.
+func (cache *Cache[K, V]) fetchMissing(arrK []K, found []bool, expiresIn time.Duration) error {
 	var keysToFetch []K
-	for _, key := range arrK {
-		_, ok := cache.Get(key)
-		if !ok {
-			keysToFetch = append(keysToFetch, key)
+	requested := make(map[K]struct{}, len(arrK))
+	for i, key := range arrK {
+		if _, ok := requested[key]; ok || found[i] {
+			continue
 		}
+		requested[key] = struct{}{}
+		keysToFetch = append(keysToFetch, key)
+	}
+	if len(keysToFetch) == 0 {
+		return nil
 	}
 
-	values, err := cache.fetcher.FetchMany(keysToFetch)
+	batch, err := cache.fetchKeyed(keysToFetch)
 	if err != nil {
 		return err
 	}
+	keys, values := cache.dropZeros(batch.keys, batch.values)
 
-	for _, value := range values {
-		e := entry[V]{
-			value:     value,
-			expiresAt: expiresAt,
+	fetched := make(map[K]struct{}, len(values))
+	var admittedKeys []K
+	var admitted []V
+	for i, key := range keys {
+		if _, ok := requested[key]; !ok && cache.strictFetchMany {
+			cache.stats.unrequestedFetches.Add(1)
+			continue
+		}
+		if _, ok := fetched[key]; ok {
+			cache.stats.duplicateFetches.Add(1)
 		}
-		cache.set(e)
+		fetched[key] = struct{}{}
+		admittedKeys = append(admittedKeys, key)
+		admitted = append(admitted, values[i])
 	}
 
+	cache.setFetched(admittedKeys, admitted, expiresIn, batch.expiries)
+
+	if batch.keyed {
+		for _, key := range keysToFetch {
+			if _, ok := fetched[key]; !ok {
+				cache.setError(key, ErrNotFound)
+			}
+		}
+	}
 	return nil
 }
+
+// SetMany writes new entries for values to the cache with expiry duration
+// expiresIn, like Set, under a single lock acquisition.
+func (cache *Cache[K, V]) SetMany(values []V, expiresIn time.Duration) {
+	cache.setMany(values, expiresIn, "")
+}
+
+// setFetched writes fetched values with the given keys like setMany, subject
+// to the cache's admission policy. Values with a key in expiries expire after
+// the duration given there instead of expiresIn. It returns the entries
+// written, with values that were not cached as already expired entries.
+func (cache *Cache[K, V]) setFetched(keys []K, values []V, expiresIn time.Duration, expiries map[K]time.Duration) []entry[V] {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entries := make([]entry[V], len(values))
+	for i, value := range values {
+		entries[i] = entry[V]{value: value, expiresAt: cache.now()}
+		ttl, ok := expiries[keys[i]]
+		if !ok {
+			ttl = expiresIn
+		}
+		if !cache.admitLocked(keys[i], value) {
+			continue
+		}
+		version := cache.version
+		cache.putLocked(keys[i], value, ttl, nil, ReasonFetched)
+		if e, ok := cache.store[keys[i]]; ok && e.version > version {
+			entries[i] = e
+		}
+	}
+	return entries
+}
+
+func (cache *Cache[K, V]) setMany(values []V, expiresIn time.Duration, reason string) {
+	keys, values := cache.keysOf(values)
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for i, value := range values {
+		cache.putLocked(keys[i], value, expiresIn, nil, reason)
+	}
+}
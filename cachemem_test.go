@@ -1,7 +1,12 @@
 package cachemem
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -34,8 +39,23 @@ func getKey(s string) int {
 	return i
 }
 
+func TestNew_invalidConfig(t *testing.T) {
+	invalid := []Option[int, string]{
+		WithCleanFrequency[int, string](-1),
+		WithMaxEntries[int, string](-1),
+		WithAdmission[int, string](NewTinyLFU[int](10)),
+		WithBypassFraction[int, string](2),
+		WithValidationFraction[int, string](0.5),
+	}
+
+	for _, opt := range invalid {
+		_, err := New[int, string](&testFetcher, getKey, opt)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	}
+}
+
 func TestCache_Set(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	value := "10"
 	cache.Set(value, time.Hour)
 
@@ -44,7 +64,7 @@ func TestCache_Set(t *testing.T) {
 }
 
 func TestCache_Get(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	value := "50"
 	cache.Set(value, time.Hour)
 
@@ -54,7 +74,7 @@ func TestCache_Get(t *testing.T) {
 }
 
 func TestCache_Get_expired(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	value := "5"
 	cache.Set(value, time.Nanosecond)
 
@@ -65,14 +85,14 @@ func TestCache_Get_expired(t *testing.T) {
 }
 
 func TestCache_Get_keyNotExists(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 
 	_, ok := cache.Get(2)
 	assert.False(t, ok)
 }
 
 func TestCache_Delete(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	cache.Set("3", time.Hour)
 	cache.Delete(3)
 
@@ -80,8 +100,31 @@ func TestCache_Delete(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestCache_DeleteMany(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.SetMany([]string{"1", "2", "3"}, time.Hour)
+
+	cache.DeleteMany([]int{1, 3, 4})
+
+	assert.Equal(t, []int{2}, cache.Keys())
+}
+
+func TestCache_DeleteWhere(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	for i := 1; i <= 4; i++ {
+		cache.Set(strconv.Itoa(i), time.Hour)
+	}
+
+	deleted := cache.DeleteWhere(func(k int, v string) bool {
+		return k%2 == 0
+	})
+
+	assert.Equal(t, 2, deleted)
+	assert.ElementsMatch(t, []int{1, 3}, cache.Keys())
+}
+
 func TestCache_Clear(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	cache.Set("1", time.Hour)
 	cache.Set("2", time.Hour)
 
@@ -94,7 +137,7 @@ func TestCache_Clear(t *testing.T) {
 }
 
 func TestCache_Length(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	cache.Set("1", 1)
 	cache.Set("2", 2)
 
@@ -103,7 +146,7 @@ func TestCache_Length(t *testing.T) {
 }
 
 func TestCache_GetOrFetch(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	actual, err := cache.GetOrFetch(2, time.Hour)
 	assert.Equal(t, "2", actual)
 	assert.NoError(t, err)
@@ -114,7 +157,7 @@ func TestCache_GetOrFetch(t *testing.T) {
 }
 
 func TestCache_FetchMany(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	cache.Set("1", time.Hour)
 	cache.Set("3", time.Hour)
 	err := cache.FetchMany([]int{1, 2, 3, 4}, time.Hour)
@@ -131,8 +174,45 @@ func TestCache_FetchMany(t *testing.T) {
 	assert.Subset(t, testFetcher.FetchManyCalls[0], []int{2, 4})
 }
 
+func TestCache_FetchMany_duplicateKeys(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey)
+
+	err := cache.FetchMany([]int{1, 2, 1, 2}, time.Hour)
+
+	assert.NoError(t, err)
+	require.Len(t, fetcher.FetchManyCalls, 1)
+	assert.Equal(t, []int{1, 2}, fetcher.FetchManyCalls[0])
+}
+
+func TestCache_FetchMany_duplicateValues(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, fixedKey)
+
+	err := cache.FetchMany([]int{2, 3}, time.Hour)
+	actual, _ := cache.Get(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3", actual)
+	assert.Equal(t, uint64(1), cache.Stats().DuplicateFetches)
+}
+
+func TestCache_FetchMany_strict(t *testing.T) {
+	fetcher := TestFetcher{}
+	tenfold := func(s string) int { return getKey(s) * 10 }
+	cache, _ := New[int, string](&fetcher, tenfold, WithStrictFetchMany[int, string]())
+
+	err := cache.FetchMany([]int{1, 10}, time.Hour)
+	actual, _ := cache.Get(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, uint64(1), cache.Stats().UnrequestedFetches)
+}
+
 func TestCache_GetMany(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Second)
+	cache, _ := New[int, string](&testFetcher, getKey)
 	cache.Set("1", time.Hour)
 	cache.Set("2", time.Nanosecond)
 	cache.Set("3", time.Hour)
@@ -145,10 +225,437 @@ func TestCache_GetMany(t *testing.T) {
 }
 
 func TestCache_StartCleaning(t *testing.T) {
-	cache := New[int, string](&testFetcher, getKey, time.Millisecond)
+	cache, _ := New[int, string](&testFetcher, getKey, WithCleanFrequency[int, string](time.Millisecond))
 	cache.Set("100", time.Nanosecond)
 	go cache.StartCleaning()
 	time.Sleep(2 * time.Millisecond)
 	cache.StopCleaning()
 	assert.Equal(t, 0, cache.Len())
 }
+
+func TestCache_EvictionCandidates(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", 3*time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Set("3", time.Nanosecond)
+	cache.Set("4", 2*time.Hour)
+
+	actual := cache.EvictionCandidates(3)
+
+	assert.Equal(t, []int{3, 2, 4}, actual)
+	assert.Equal(t, 4, cache.Len())
+}
+
+var errFetch = errors.New("fetch failed")
+
+type FailingFetcher struct {
+	FetchOneCalls int
+}
+
+func (fetcher *FailingFetcher) FetchOne(i int) (string, error) {
+	fetcher.FetchOneCalls++
+	return "", fmt.Errorf("fetching %d: %w", i, errFetch)
+}
+
+func (fetcher *FailingFetcher) FetchMany(arrI []int) ([]string, error) {
+	return nil, errFetch
+}
+
+func TestCache_GetOrFetch_negativeTTL(t *testing.T) {
+	fetcher := FailingFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithNegativeTTL[int, string](time.Hour))
+
+	_, err1 := cache.GetOrFetch(1, time.Hour)
+	_, err2 := cache.GetOrFetch(1, time.Hour)
+	_, ok := cache.Get(1)
+
+	assert.ErrorIs(t, err1, errFetch)
+	assert.Equal(t, err1, err2)
+	assert.False(t, ok)
+	assert.Equal(t, 1, fetcher.FetchOneCalls)
+	assert.Equal(t, uint64(2), cache.Stats().NegativeHits)
+	assert.Equal(t, uint64(0), cache.Stats().Hits)
+}
+
+func TestCache_GetOrFetch_negativeTTLExpired(t *testing.T) {
+	fetcher := FailingFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithNegativeTTL[int, string](time.Nanosecond))
+
+	_, _ = cache.GetOrFetch(1, time.Hour)
+	time.Sleep(10 * time.Nanosecond)
+	_, err := cache.GetOrFetch(1, time.Hour)
+
+	assert.ErrorIs(t, err, errFetch)
+	assert.Equal(t, 2, fetcher.FetchOneCalls)
+}
+
+func TestCache_GetOrFetch_error(t *testing.T) {
+	fetcher := FailingFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey)
+
+	_, _ = cache.GetOrFetch(1, time.Hour)
+	_, err := cache.GetOrFetch(1, time.Hour)
+
+	assert.ErrorIs(t, err, errFetch)
+	assert.Equal(t, 2, fetcher.FetchOneCalls)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_GetOrFetch_negativeTTLFunc(t *testing.T) {
+	fetcher := FailingFetcher{}
+	classify := func(err error) time.Duration {
+		if errors.Is(err, errFetch) {
+			return 0
+		}
+		return time.Hour
+	}
+	cache, _ := New[int, string](&fetcher, getKey, WithNegativeTTLFunc[int, string](classify))
+
+	_, _ = cache.GetOrFetch(1, time.Hour)
+	_, _ = cache.GetOrFetch(1, time.Hour)
+
+	assert.Equal(t, 2, fetcher.FetchOneCalls)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_Set_zeroExpiry(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.Set("1", 0)
+	cache.Set("2", -time.Hour)
+
+	_, ok := cache.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_GetOrFetch_zeroExpiry(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	actual, err := cache.GetOrFetch(1, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_Set_longExpiry(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Duration(math.MaxInt64))
+
+	_, ok := cache.Get(1)
+	assert.True(t, ok)
+}
+
+func BenchmarkCache_Set(b *testing.B) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	values := make([]string, 1024)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Set(values[i%len(values)], time.Hour)
+	}
+}
+
+func BenchmarkCache_Get(b *testing.B) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	for i := 0; i < 1024; i++ {
+		cache.Set(strconv.Itoa(i), time.Hour)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get(i % 1024)
+	}
+}
+
+func BenchmarkCache_Get_parallel(b *testing.B) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	for i := 0; i < 1024; i++ {
+		cache.Set(strconv.Itoa(i), time.Hour)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Get(i % 1024)
+		}
+	})
+}
+
+func TestCache_Get_concurrentWrites(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				cache.Set(strconv.Itoa(i), time.Hour)
+				cache.Get(i)
+				cache.GetMany([]int{i, i + 1})
+				cache.Delete(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCache_SetMany(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithInitialCapacity[int, string](10))
+	cache.SetMany([]string{"1", "2"}, time.Hour)
+
+	actual := cache.GetMany([]int{1, 2})
+
+	assert.Equal(t, []string{"1", "2"}, actual)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCache_GetMany_stats(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+
+	actual := cache.GetMany([]int{2, 3, 1})
+
+	assert.Equal(t, []string{"2", "1"}, actual)
+	assert.Equal(t, uint64(2), cache.Stats().Hits)
+	assert.Equal(t, uint64(1), cache.Stats().Misses)
+}
+
+func BenchmarkCache_GetMany(b *testing.B) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	keys := make([]int, 1000)
+	for i := range keys {
+		keys[i] = i
+		cache.Set(strconv.Itoa(i), time.Hour)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.GetMany(keys)
+	}
+}
+
+func TestCache_Get_readThrough(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithReadThrough[int, string](time.Hour))
+
+	actual, ok := cache.Get(7)
+
+	assert.True(t, ok)
+	assert.Equal(t, "7", actual)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestCache_Get_readThroughFailed(t *testing.T) {
+	fetcher := FailingFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithReadThrough[int, string](time.Hour))
+
+	_, ok := cache.Get(7)
+
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_GetE(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	actual, err := cache.GetE(1)
+	_, missingErr := cache.GetE(2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.ErrorIs(t, missingErr, ErrNotFound)
+}
+
+func TestCache_GetE_readThroughFailed(t *testing.T) {
+	fetcher := FailingFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithReadThrough[int, string](time.Hour))
+
+	_, err := cache.GetE(7)
+
+	assert.ErrorIs(t, err, errFetch)
+}
+
+func TestNew_withJanitor(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithCleanFrequency[int, string](time.Millisecond),
+		WithJanitor[int, string](),
+	)
+	defer cache.Close()
+	cache.Set("100", time.Nanosecond)
+	time.Sleep(2 * time.Millisecond)
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	assert.Equal(t, 0, len(cache.store))
+}
+
+func TestNew_withJanitor_stopCleaning(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithCleanFrequency[int, string](time.Millisecond),
+		WithJanitor[int, string](),
+	)
+	defer cache.Close()
+
+	running := cache.CleaningStatus().Running
+	err := cache.StopCleaningContext(context.Background())
+
+	assert.True(t, running)
+	assert.NoError(t, err)
+	assert.False(t, cache.CleaningStatus().Running)
+}
+
+func TestNew_withoutJanitor(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithCleanFrequency[int, string](time.Millisecond))
+
+	assert.False(t, cache.CleaningStatus().Running)
+}
+
+func TestCache_StartCleaningContext(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithCleanFrequency[int, string](time.Millisecond))
+	cache.Set("100", time.Nanosecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		cache.StartCleaningContext(ctx)
+		close(done)
+	}()
+	time.Sleep(2 * time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_StopCleaning_notCleaning(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.StopCleaning()
+}
+
+func TestCache_CleaningStatus(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithCleanFrequency[int, string](time.Millisecond))
+	cache.Set("100", time.Nanosecond)
+	go cache.StartCleaning()
+	time.Sleep(5 * time.Millisecond)
+
+	running := cache.CleaningStatus()
+	err := cache.StopCleaningContext(context.Background())
+	stopped := cache.CleaningStatus()
+
+	assert.NoError(t, err)
+	assert.True(t, running.Running)
+	assert.False(t, running.LastPass.IsZero())
+	assert.Equal(t, uint64(1), running.Removed)
+	assert.False(t, stopped.Running)
+}
+
+func TestCache_StopCleaningContext_notCleaning(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, cache.StopCleaningContext(ctx))
+}
+
+func TestNewStatic(t *testing.T) {
+	cache, _ := NewStatic[int, string](getKey)
+	cache.Set("1", time.Hour)
+
+	actual, err := cache.GetOrFetch(1, time.Hour)
+	_, missingErr := cache.GetOrFetch(2, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.ErrorIs(t, missingErr, ErrNoFetcher)
+	assert.ErrorIs(t, cache.FetchMany([]int{3}, time.Hour), ErrNoFetcher)
+}
+
+func TestNewStatic_requiresFetcher(t *testing.T) {
+	opts := []Option[int, string]{
+		WithReadThrough[int, string](time.Hour),
+		WithBatchWindow[int, string](time.Millisecond),
+		WithBypassFraction[int, string](1),
+		WithValidationFraction[int, string](1),
+		WithPredictivePrefetch[int, string](10),
+	}
+	for _, opt := range opts {
+		_, err := NewStatic[int, string](getKey, opt, WithEqual[int, string](func(a, b string) bool { return a == b }), WithDefaultTTL[int, string](time.Hour))
+
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorContains(t, err, "requires a fetcher")
+	}
+}
+
+func TestCache_SetForever(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.SetForever("1")
+	cache.Set("2", NoExpiry)
+	cache.Set("3", time.Hour)
+	cache.Delete(2)
+
+	cache.mutex.Lock()
+	e := cache.store[1]
+	cache.mutex.Unlock()
+
+	assert.False(t, e.hasExpired(math.MaxInt64))
+	assert.Equal(t, 1, cache.Stats().Permanent)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCache_WithTTLJitter(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithTTLJitter[int, string](0.5))
+
+	for i := 0; i < 100; i++ {
+		before := cache.now()
+		expiresAt := cache.expiry(time.Hour)
+		after := cache.now()
+		assert.GreaterOrEqual(t, expiresAt, before+int64(time.Hour/2))
+		assert.LessOrEqual(t, expiresAt, after+int64(time.Hour))
+	}
+	assert.Equal(t, int64(math.MaxInt64), cache.expiry(NoExpiry))
+}
+
+func TestCache_WithOnExpire(t *testing.T) {
+	expired := map[int]string{}
+	var cache *Cache[int, string]
+	cache, _ = New[int, string](
+		&testFetcher,
+		getKey,
+		WithClockResolution[int, string](time.Hour),
+		WithOnExpire[int, string](func(k int, v string) {
+			expired[k] = v
+			cache.Set("3", time.Hour)
+		}),
+		WithJanitor[int, string](),
+	)
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.Set("2", time.Hour)
+	cache.Set("4", time.Minute)
+	cache.Delete(4)
+
+	advance(cache, 2*time.Minute)
+	removed := cache.clean()
+
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, map[int]string{1: "1"}, expired)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCache_WithOnExpire_withoutCleaner(t *testing.T) {
+	_, err := New[int, string](&testFetcher, getKey, WithOnExpire[int, string](func(int, string) {}))
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
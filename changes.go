@@ -0,0 +1,44 @@
+package cachemem
+
+import "time"
+
+// ChangeOp is the kind of a Change.
+type ChangeOp int
+
+const (
+	// ChangeUpsert writes the changed record.
+	ChangeUpsert ChangeOp = iota
+	// ChangeDelete deletes the changed record.
+	ChangeDelete
+)
+
+// Change is a change to a record made upstream of the cache, such as one
+// produced by change data capture or a webhook.
+type Change[K comparable, V any] struct {
+	Op ChangeOp
+	// Key is the key of the record to delete. Upserted records are stored
+	// under the key of Value.
+	Key K
+	// Value is the record to upsert.
+	Value V
+	// ExpiresIn is the expiry duration of the upserted record.
+	ExpiresIn time.Duration
+}
+
+// ApplyChanges applies changes to the cache in order, under a single lock
+// acquisition, so that readers observe either none or all of them.
+func (cache *Cache[K, V]) ApplyChanges(changes []Change[K, V]) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, change := range changes {
+		switch change.Op {
+		case ChangeUpsert:
+			if key, err := cache.keyOf(change.Value); err == nil {
+				cache.putLocked(key, change.Value, change.ExpiresIn, nil, ReasonChanged)
+			}
+		case ChangeDelete:
+			cache.deleteLocked(change.Key, ReasonChanged)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_ApplyChanges(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithHistory[int, string](10))
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+
+	cache.ApplyChanges([]Change[int, string]{
+		{Op: ChangeUpsert, Value: "3", ExpiresIn: time.Hour},
+		{Op: ChangeDelete, Key: 1},
+	})
+
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+	value3, ok3 := cache.Get(3)
+
+	assert.False(t, ok1)
+	assert.False(t, ok2)
+	assert.True(t, ok3)
+	assert.Equal(t, "3", value3)
+	assert.Equal(t, ReasonChanged, cache.History(3)[0].Reason)
+}
@@ -0,0 +1,41 @@
+package cachemem
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// coarseClock caches the time since a cache's epoch, refreshed by a
+// background ticker, so that reading the time costs an atomic load rather
+// than a call to time.Now.
+type coarseClock struct {
+	now  atomic.Int64
+	stop chan struct{}
+}
+
+// startClock starts a coarse clock for the cache updated every resolution.
+func (cache *Cache[K, V]) startClock(resolution time.Duration) {
+	clock := &coarseClock{stop: make(chan struct{})}
+	clock.now.Store(int64(time.Since(cache.epoch)))
+	cache.clock = clock
+
+	go func() {
+		ticker := time.NewTicker(resolution)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				clock.now.Store(int64(time.Since(cache.epoch)))
+			case <-clock.stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopClock stops the cache's coarse clock, if it has one.
+func (cache *Cache[K, V]) stopClock() {
+	if cache.clock != nil {
+		close(cache.clock.stop)
+	}
+}
@@ -0,0 +1,41 @@
+package cachemem
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithClockResolution(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithClockResolution[int, string](time.Millisecond))
+	defer cache.Close()
+	cache.Set("1", 5*time.Millisecond)
+
+	_, okBefore := cache.Get(1)
+	time.Sleep(20 * time.Millisecond)
+	_, okAfter := cache.Get(1)
+
+	assert.True(t, okBefore)
+	assert.False(t, okAfter)
+}
+
+func BenchmarkCache_Get_coarseClock(b *testing.B) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithClockResolution[int, string](time.Millisecond))
+	defer cache.Close()
+	for i := 0; i < 1024; i++ {
+		cache.Set(strconv.Itoa(i), time.Hour)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get(i % 1024)
+	}
+}
+
+// advance moves the time seen by a cache with a coarse clock forward by d.
+func advance[K comparable, V any](cache *Cache[K, V], d time.Duration) {
+	cache.clock.now.Add(int64(d))
+}
@@ -0,0 +1,45 @@
+package cachemem
+
+import "time"
+
+// Close stops the cleaner started by StartCleaning or WithJanitor, waiting
+// for a clean in progress to finish, and releases the records held by the
+// cache. Callbacks such as the one set WithOnEvict are called synchronously,
+// so none are pending once Close returns, and must not call Close.
+//
+// After Close, operations that return an error return ErrClosed, writes are
+// dropped and reads miss. Close returns ErrClosed if the cache is already
+// closed.
+func (cache *Cache[K, V]) Close() error {
+	if !cache.closed.CompareAndSwap(false, true) {
+		return ErrClosed
+	}
+
+	if done := cache.stopCleaning(); done != nil {
+		<-done
+	}
+	cache.stopClock()
+	cache.closeExpiredQueue()
+
+	cache.mutex.Lock()
+	cache.store = map[K]entry[V]{}
+	cache.peak = 0
+	cache.recency = newRecency[K](cache.recency != nil)
+	cache.cost = 0
+	cache.costs = costHistogram{}
+	cache.permanent = 0
+	cache.dependents = map[K]map[K]struct{}{}
+	cache.dependencies = map[K][]K{}
+	cache.derivations = map[K]derivation[K, V]{}
+	cache.ttlOverrides = map[K]time.Duration{}
+	if cache.index != nil {
+		cache.index.reset()
+	}
+	cache.mutex.Unlock()
+	return nil
+}
+
+// IsClosed reports whether the cache has been closed.
+func (cache *Cache[K, V]) IsClosed() bool {
+	return cache.closed.Load()
+}
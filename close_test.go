@@ -0,0 +1,64 @@
+package cachemem
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Close(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	err := cache.Close()
+	cache.Set("2", time.Hour)
+	_, ok := cache.Get(1)
+	_, fetchErr := cache.GetOrFetch(3, time.Hour)
+
+	assert.NoError(t, err)
+	assert.True(t, cache.IsClosed())
+	assert.False(t, ok)
+	assert.ErrorIs(t, fetchErr, ErrClosed)
+	assert.ErrorIs(t, cache.FetchMany([]int{4}, time.Hour), ErrClosed)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_Close_twice(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	assert.NoError(t, cache.Close())
+	assert.ErrorIs(t, cache.Close(), ErrClosed)
+}
+
+func TestCache_Close_stopsCleaning(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithCleanFrequency[int, string](time.Millisecond))
+	done := make(chan struct{})
+	go func() {
+		cache.StartCleaning()
+		close(done)
+	}()
+	time.Sleep(2 * time.Millisecond)
+
+	assert.NoError(t, cache.Close())
+	<-done
+}
+
+func TestCache_Close_waitsForCleaning(t *testing.T) {
+	var started, finished atomic.Bool
+	cache, _ := New[int, string](&testFetcher, getKey,
+		WithCleanFrequency[int, string](time.Millisecond),
+		WithJanitor[int, string](),
+		WithOnExpire[int, string](func(int, string) {
+			started.Store(true)
+			time.Sleep(20 * time.Millisecond)
+			finished.Store(true)
+		}),
+	)
+	cache.Set("1", time.Nanosecond)
+	assert.Eventually(t, started.Load, time.Second, time.Millisecond)
+
+	assert.NoError(t, cache.Close())
+	assert.True(t, finished.Load())
+}
@@ -0,0 +1,27 @@
+package cachemem
+
+const (
+	// minCompactionSize is the smallest peak record count for which the store
+	// is compacted, below which the memory reclaimed isn't worth a rebuild.
+	minCompactionSize = 1024
+	// compactionRatio is how many times smaller than its peak record count the
+	// store must become before it is compacted.
+	compactionRatio = 4
+)
+
+// compactLocked rebuilds the store into a right-sized map once it holds a
+// small fraction of the records it once held, since Go maps never shrink.
+// The caller must hold the mutex.
+func (cache *Cache[K, V]) compactLocked() {
+	if cache.peak < minCompactionSize || len(cache.store) > cache.peak/compactionRatio {
+		return
+	}
+
+	store := make(map[K]entry[V], max(len(cache.store), cache.initialCapacity))
+	for key, e := range cache.store {
+		store[key] = e
+	}
+	cache.store = store
+	cache.peak = len(store)
+	cache.stats.compactions.Add(1)
+}
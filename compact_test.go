@@ -0,0 +1,38 @@
+package cachemem
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_clean_compacts(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	for i := 0; i < 2000; i++ {
+		expiresIn := time.Nanosecond
+		if i < 10 {
+			expiresIn = time.Hour
+		}
+		cache.Set(strconv.Itoa(i), expiresIn)
+	}
+
+	time.Sleep(10 * time.Nanosecond)
+	cache.clean()
+
+	assert.Equal(t, 10, cache.Len())
+	assert.Equal(t, 10, cache.peak)
+	assert.Equal(t, uint64(1), cache.Stats().Compactions)
+}
+
+func TestCache_clean_noCompaction(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	for i := 0; i < 2000; i++ {
+		cache.Set(strconv.Itoa(i), time.Hour)
+	}
+
+	cache.clean()
+
+	assert.Equal(t, uint64(0), cache.Stats().Compactions)
+}
@@ -0,0 +1,69 @@
+package cachemem
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// costHistogram counts the records in a cache by cost, in buckets of powers
+// of two: bucket i holds records with a cost c such that bits.Len64(c) == i,
+// and bucket 0 records with a cost of zero or less.
+type costHistogram [65]int
+
+func costBucket(cost int64) int {
+	if cost <= 0 {
+		return 0
+	}
+	return bits.Len64(uint64(cost))
+}
+
+func (h *costHistogram) add(cost int64) {
+	h[costBucket(cost)]++
+}
+
+func (h *costHistogram) remove(cost int64) {
+	h[costBucket(cost)]--
+}
+
+// buckets returns the counts up to the last non-empty bucket.
+func (h *costHistogram) buckets() []int {
+	n := len(h)
+	for n > 0 && h[n-1] == 0 {
+		n--
+	}
+	if n == 0 {
+		return nil
+	}
+	return append([]int(nil), h[:n]...)
+}
+
+// EntryCost is the cost of a record, see WithWeigher.
+type EntryCost[K comparable] struct {
+	Key  K
+	Cost int64
+}
+
+// LargestEntries returns the keys and costs of the n records with the highest
+// cost, most costly first, so that a cache exceeding its memory budget can be
+// attributed to specific keys. Records whose cost is tied are returned in no
+// particular order.
+func (cache *Cache[K, V]) LargestEntries(n int) []EntryCost[K] {
+	if n <= 0 {
+		return nil
+	}
+
+	cache.mutex.RLock()
+	entries := make([]EntryCost[K], 0, len(cache.store))
+	for key, e := range cache.store {
+		entries = append(entries, EntryCost[K]{Key: key, Cost: e.cost})
+	}
+	cache.mutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Cost > entries[j].Cost
+	})
+	if n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
@@ -0,0 +1,40 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_LargestEntries(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithWeigher[int, string](weighLength))
+	cache.Set("1", time.Hour)
+	cache.Set("22", time.Hour)
+	cache.Set("55555", time.Hour)
+	cache.Set("333", time.Hour)
+
+	actual := cache.LargestEntries(2)
+
+	assert.Equal(t, []EntryCost[int]{{Key: 55555, Cost: 5}, {Key: 333, Cost: 3}}, actual)
+	assert.Nil(t, cache.LargestEntries(0))
+	assert.Len(t, cache.LargestEntries(10), 4)
+}
+
+func TestCache_Stats_costHistogram(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithWeigher[int, string](weighLength))
+	assert.Nil(t, cache.Stats().CostHistogram)
+
+	cache.Set("1", time.Hour)
+	cache.Set("22", time.Hour)
+	cache.Set("333", time.Hour)
+	cache.Set("55555", time.Hour)
+	assert.Equal(t, []int{0, 1, 2, 1}, cache.Stats().CostHistogram)
+
+	cache.Delete(55555)
+	cache.Set("333", time.Hour)
+	assert.Equal(t, []int{0, 1, 2}, cache.Stats().CostHistogram)
+
+	cache.Clear()
+	assert.Nil(t, cache.Stats().CostHistogram)
+}
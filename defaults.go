@@ -0,0 +1,19 @@
+package cachemem
+
+// SetDefault writes a new entry to the cache like Set, expiring after the
+// duration set WithDefaultTTL.
+func (cache *Cache[K, V]) SetDefault(value V) {
+	cache.Set(value, cache.defaultTTL)
+}
+
+// GetOrFetchDefault is like GetOrFetch, caching fetched records for the
+// duration set WithDefaultTTL.
+func (cache *Cache[K, V]) GetOrFetchDefault(key K) (V, error) {
+	return cache.GetOrFetch(key, cache.defaultTTL)
+}
+
+// FetchManyDefault is like FetchMany, caching fetched records for the
+// duration set WithDefaultTTL.
+func (cache *Cache[K, V]) FetchManyDefault(arrK []K) error {
+	return cache.FetchMany(arrK, cache.defaultTTL)
+}
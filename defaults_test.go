@@ -0,0 +1,32 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithDefaultTTL(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithDefaultTTL[int, string](time.Nanosecond))
+
+	cache.SetDefault("1")
+	_, err := cache.GetOrFetchDefault(2)
+	fetchManyErr := cache.FetchManyDefault([]int{3})
+	time.Sleep(time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.NoError(t, fetchManyErr)
+	assert.Empty(t, cache.GetMany([]int{1, 2, 3}))
+}
+
+func TestCache_SetDefault_noDefaultTTL(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	cache.SetDefault("1")
+	actual, ok := cache.Get(1)
+
+	assert.True(t, ok)
+	assert.Equal(t, "1", actual)
+}
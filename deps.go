@@ -0,0 +1,205 @@
+package cachemem
+
+import "time"
+
+type derivation[K comparable, V any] struct {
+	deps      []K
+	compute   func([]V) (V, error)
+	expiresIn time.Duration
+}
+
+// SetWithDeps writes a new entry to the cache with expiry duration expiresIn
+// that depends on the records with keys deps. When any of deps is updated or
+// deleted, the entry is deleted too, and it expires no later than any of
+// deps. Invalidation is transitive, so entries depending on the deleted entry
+// are also deleted.
+func (cache *Cache[K, V]) SetWithDeps(value V, expiresIn time.Duration, deps ...K) {
+	cache.set(value, expiresIn, deps, "")
+}
+
+// Derive registers key as an entry computed from the records with keys deps,
+// and computes it. Whenever any of deps is updated or deleted, the derived
+// entry is invalidated and then recomputed on its next read. compute receives
+// the dependency values in the same order as deps.
+//
+// The derivation remains registered when computing fails, so the entry is
+// retried on its next read. Derive returns ErrCyclicDependency if key is
+// among deps, or deps are derived from key directly or indirectly.
+func (cache *Cache[K, V]) Derive(key K, deps []K, compute func([]V) (V, error), expiresIn time.Duration) error {
+	if cache.closed.Load() {
+		return ErrClosed
+	}
+
+	cache.mutex.Lock()
+	if cache.derivesFromLocked(deps, key) {
+		cache.mutex.Unlock()
+		return ErrCyclicDependency
+	}
+	cache.derivations[key] = derivation[K, V]{
+		deps:      deps,
+		compute:   compute,
+		expiresIn: expiresIn,
+	}
+	cache.mutex.Unlock()
+
+	_, err := cache.recompute(key)
+	return err
+}
+
+// derivesFromLocked reports whether any of keys is key, or is derived,
+// directly or indirectly, from key. The caller must hold the mutex.
+func (cache *Cache[K, V]) derivesFromLocked(keys []K, key K) bool {
+	visited := map[K]struct{}{}
+	for len(keys) > 0 {
+		k := keys[len(keys)-1]
+		keys = keys[:len(keys)-1]
+		if k == key {
+			return true
+		}
+		if _, ok := visited[k]; ok {
+			continue
+		}
+		visited[k] = struct{}{}
+		keys = append(keys, cache.derivations[k].deps...)
+	}
+	return false
+}
+
+// recompute computes and caches the derived entry with the given key,
+// returning it. The entry is only cached if none of its dependencies were
+// written while it was computed, since it would otherwise outlive the
+// values it was computed from. An entry that was not cached is returned as
+// already expired.
+func (cache *Cache[K, V]) recompute(key K) (entry[V], error) {
+	cache.mutex.RLock()
+	d, ok := cache.derivations[key]
+	cache.mutex.RUnlock()
+	if !ok {
+		return entry[V]{}, errNotDerived
+	}
+
+	values := make([]V, 0, len(d.deps))
+	versions := make([]uint64, 0, len(d.deps))
+	for _, dep := range d.deps {
+		e, ok := cache.get(dep)
+		if !ok && cache.readThroughTTL > 0 {
+			if _, err := cache.fetch(dep, cache.readThroughTTL); err == nil {
+				e, ok = cache.lookup(dep)
+			}
+		}
+		if !ok || e.err != nil {
+			return entry[V]{}, ErrMissingDependency
+		}
+		values = append(values, e.value)
+		versions = append(versions, e.version)
+	}
+
+	value, err := d.compute(values)
+	if err != nil {
+		return entry[V]{}, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := cache.now()
+	for i, dep := range d.deps {
+		e, ok := cache.store[dep]
+		if !ok || e.hasExpired(now) || e.version != versions[i] {
+			return entry[V]{value: value, expiresAt: now}, nil
+		}
+	}
+	version := cache.version
+	cache.putLocked(key, value, d.expiresIn, d.deps, ReasonDerived)
+	if e, ok := cache.store[key]; ok && e.version > version {
+		return e, nil
+	}
+	return entry[V]{value: value, expiresAt: now}, nil
+}
+
+// capToDepsLocked brings the expiry of e forward to that of the earliest
+// expiring of deps, so that an entry never outlives the entries it depends
+// on. The caller must hold the mutex.
+func (cache *Cache[K, V]) capToDepsLocked(e *entry[V], deps []K) {
+	for _, dep := range deps {
+		d, ok := cache.store[dep]
+		if !ok {
+			continue
+		}
+		e.expiresAt = min(e.expiresAt, d.expiresAt)
+		if e.idle > 0 {
+			e.limit = min(e.limit, d.expiresAt)
+		}
+	}
+}
+
+// capDependentsLocked caps the expiry of the entries depending on key,
+// directly or indirectly, to that of their dependencies, after the expiry of
+// key was brought forward. The caller must hold the mutex.
+func (cache *Cache[K, V]) capDependentsLocked(key K) {
+	keys := []K{key}
+	visited := map[K]struct{}{key: {}}
+	for len(keys) > 0 {
+		k := keys[len(keys)-1]
+		keys = keys[:len(keys)-1]
+		for dependent := range cache.dependents[k] {
+			if _, ok := visited[dependent]; ok {
+				continue
+			}
+			visited[dependent] = struct{}{}
+			e, ok := cache.store[dependent]
+			if !ok {
+				continue
+			}
+			wasPermanent := e.permanent()
+			cache.capToDepsLocked(&e, cache.dependencies[dependent])
+			if wasPermanent && !e.permanent() {
+				cache.permanent--
+			}
+			cache.store[dependent] = e
+			keys = append(keys, dependent)
+		}
+	}
+}
+
+// link records that key depends on each of deps.
+// The caller must hold the mutex.
+func (cache *Cache[K, V]) link(key K, deps []K) {
+	if len(deps) == 0 {
+		return
+	}
+
+	cache.dependencies[key] = deps
+	for _, dep := range deps {
+		dependents, ok := cache.dependents[dep]
+		if !ok {
+			dependents = map[K]struct{}{}
+			cache.dependents[dep] = dependents
+		}
+		dependents[key] = struct{}{}
+	}
+}
+
+// unlink removes the dependencies declared by key.
+// The caller must hold the mutex.
+func (cache *Cache[K, V]) unlink(key K) {
+	for _, dep := range cache.dependencies[key] {
+		dependents := cache.dependents[dep]
+		delete(dependents, key)
+		if len(dependents) == 0 {
+			delete(cache.dependents, dep)
+		}
+	}
+	delete(cache.dependencies, key)
+}
+
+// invalidateDependents deletes every entry that depends on key.
+// The caller must hold the mutex.
+func (cache *Cache[K, V]) invalidateDependents(key K) {
+	dependents := cache.dependents[key]
+	delete(cache.dependents, key)
+
+	for dependent := range dependents {
+		cache.deleteLocked(dependent, ReasonInvalidated)
+	}
+}
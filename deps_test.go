@@ -0,0 +1,172 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetWithDeps(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+
+	actual, ok := cache.Get(2)
+	assert.Equal(t, "2", actual)
+	assert.True(t, ok)
+}
+
+func TestCache_SetWithDeps_depUpdated(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+
+	cache.Set("1", time.Hour)
+	_, ok := cache.Get(2)
+
+	assert.False(t, ok)
+}
+
+func TestCache_SetWithDeps_transitive(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+	cache.SetWithDeps("3", time.Hour, 2)
+	cache.Set("4", time.Hour)
+
+	cache.Delete(1)
+	_, ok2 := cache.Get(2)
+	_, ok3 := cache.Get(3)
+	_, ok4 := cache.Get(4)
+
+	assert.False(t, ok2)
+	assert.False(t, ok3)
+	assert.True(t, ok4)
+}
+
+func TestCache_SetWithDeps_reset(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+	cache.Set("2", time.Hour)
+
+	cache.Delete(1)
+	_, ok := cache.Get(2)
+
+	assert.True(t, ok)
+}
+
+func concat(values []string) (string, error) {
+	var s string
+	for _, v := range values {
+		s += v
+	}
+	return s, nil
+}
+
+func TestCache_Derive(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+
+	err := cache.Derive(100, []int{1, 2}, concat, time.Hour)
+	actual, ok := cache.Get(100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "12", actual)
+	assert.True(t, ok)
+}
+
+func TestCache_Derive_depUpdated(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	_ = cache.Derive(100, []int{1, 2}, concat, time.Hour)
+
+	cache.Delete(2)
+	_, ok := cache.Get(100)
+	assert.False(t, ok)
+
+	cache.Set("2", time.Hour)
+	actual, ok := cache.Get(100)
+	assert.Equal(t, "12", actual)
+	assert.True(t, ok)
+}
+
+func TestCache_Derive_missingDependency(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	err := cache.Derive(100, []int{1, 2}, concat, time.Hour)
+	assert.ErrorIs(t, err, ErrMissingDependency)
+}
+
+func TestCache_Derive_cyclic(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	err := cache.Derive(100, []int{1, 100}, concat, time.Hour)
+	assert.ErrorIs(t, err, ErrCyclicDependency)
+}
+
+func TestCache_Derive_indirectlyCyclic(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	_ = cache.Derive(100, []int{200}, concat, time.Hour)
+	_ = cache.Derive(200, []int{300}, concat, time.Hour)
+
+	err := cache.Derive(300, []int{1, 100}, concat, time.Hour)
+	_, ok := cache.Get(300)
+
+	assert.ErrorIs(t, err, ErrCyclicDependency)
+	assert.False(t, ok)
+}
+
+func TestCache_Derive_depWrittenWhileComputing(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	err := cache.Derive(100, []int{1}, func(values []string) (string, error) {
+		cache.Set("1", time.Hour)
+		return values[0] + "!", nil
+	}, time.Hour)
+	_, cached := cache.Peek(100)
+
+	assert.NoError(t, err)
+	assert.False(t, cached)
+}
+
+func TestCache_SetWithDeps_depExpires(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithClockResolution[int, string](time.Hour))
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.SetWithDeps("2", time.Hour, 1)
+	_ = cache.Derive(100, []int{1}, concat, time.Hour)
+	cache.Extend(2, time.Hour)
+
+	advance(cache, 2*time.Minute)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+	_, ok100 := cache.Peek(100)
+
+	assert.False(t, ok1)
+	assert.False(t, ok2)
+	assert.False(t, ok100)
+}
+
+func TestCache_SetWithDeps_depShortened(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithClockResolution[int, string](time.Hour))
+	defer cache.Close()
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+	cache.SetWithDeps("3", NoExpiry, 2)
+	cache.Extend(1, -59*time.Minute)
+
+	advance(cache, 2*time.Minute)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+	_, ok3 := cache.Get(3)
+
+	assert.False(t, ok1)
+	assert.False(t, ok2)
+	assert.False(t, ok3)
+}
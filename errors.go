@@ -0,0 +1,43 @@
+package cachemem
+
+import "errors"
+
+// ErrMissingDependency is returned when a derived entry cannot be computed
+// because one of its dependencies is not in the cache.
+var ErrMissingDependency = errors.New("cachemem: missing dependency")
+
+// ErrCyclicDependency is returned when a derived entry depends on itself,
+// directly or through other derived entries.
+var ErrCyclicDependency = errors.New("cachemem: cyclic dependency")
+
+// ErrNoEqual is returned when an operation compares records but the cache
+// was not initialized WithEqual.
+var ErrNoEqual = errors.New("cachemem: no equality function configured")
+
+// ErrWrongType is returned by AnyCache when given a key or value of the
+// wrong type for the underlying cache.
+var ErrWrongType = errors.New("cachemem: wrong type")
+
+// ErrInvalidConfig is returned by New when given an invalid combination of
+// options.
+var ErrInvalidConfig = errors.New("cachemem: invalid config")
+
+// ErrKeyMismatch is wrapped by KeyMismatchError.
+var ErrKeyMismatch = errors.New("cachemem: fetched record has a different key")
+
+// ErrNotFound is returned by GetE when a record is not in the cache, and by
+// GetOrFetch when the fetcher returns a zero value under ZeroValueMissing.
+var ErrNotFound = errors.New("cachemem: not found")
+
+// ErrZeroValue is returned by GetOrFetch when the fetcher returns a zero value
+// under ZeroValueReject.
+var ErrZeroValue = errors.New("cachemem: fetched zero value")
+
+// ErrNoFetcher is returned by operations that fetch records from a cache
+// created without a fetcher, such as by NewStatic.
+var ErrNoFetcher = errors.New("cachemem: no fetcher")
+
+// ErrClosed is returned by operations on a cache that has been closed.
+var ErrClosed = errors.New("cachemem: cache closed")
+
+var errNotDerived = errors.New("cachemem: not derived")
@@ -0,0 +1,124 @@
+package cachemem
+
+import "sort"
+
+// touchLocked marks key as the most recently used key, if the cache is
+// size-bounded. The caller must hold the mutex.
+func (cache *Cache[K, V]) touchLocked(key K) {
+	if cache.recency != nil {
+		cache.recency.touch(key)
+	}
+}
+
+// touch marks key as the most recently used key, if the cache is
+// size-bounded.
+func (cache *Cache[K, V]) touch(key K) {
+	if !cache.bounded {
+		return
+	}
+	if cache.accesses != nil {
+		if keys := cache.accesses.add(key); keys != nil {
+			cache.touchBuffered(keys)
+		}
+		return
+	}
+	cache.mutex.Lock()
+	if _, ok := cache.store[key]; ok {
+		cache.recency.touch(key)
+	}
+	cache.mutex.Unlock()
+}
+
+// weigh returns the cost of value.
+func (cache *Cache[K, V]) weigh(value V) (cost int64) {
+	// records whose weigher panics keep the default cost
+	cost = 1
+	if cache.weigher == nil {
+		return cost
+	}
+	defer cache.recoverPanic(nil)
+	return cache.weigher(value)
+}
+
+// overfullLocked reports whether the cache would exceed its maximum size if
+// a record of cost extra were added. The caller must hold the mutex.
+func (cache *Cache[K, V]) overfullLocked(extra int64) bool {
+	entries := len(cache.store)
+	if extra > 0 {
+		entries++
+	}
+	return (cache.maxEntries > 0 && entries > cache.maxEntries) ||
+		(cache.maxCost > 0 && cache.cost+extra > cache.maxCost)
+}
+
+// evictLocked removes least recently used records until the cache is within
+// its maximum size. The caller must hold the mutex.
+func (cache *Cache[K, V]) evictLocked() {
+	if cache.recency == nil {
+		return
+	}
+
+	for cache.overfullLocked(0) {
+		victim, ok := cache.recency.oldest()
+		if !ok {
+			return
+		}
+		e := cache.store[victim]
+		cache.deleteLocked(victim, ReasonEvicted)
+		cache.stats.evicted.Add(1)
+		cache.evicted(victim, e.value)
+	}
+}
+
+// admitLocked reports whether a fetched record with key key may be written,
+// according to the cache's admission policy. Only records that would evict
+// another record are subject to the policy. The caller must hold the mutex.
+func (cache *Cache[K, V]) admitLocked(key K, value V) bool {
+	if cache.admission == nil || !cache.overfullLocked(cache.weigh(value)) {
+		return true
+	}
+	if _, ok := cache.store[key]; ok {
+		return true
+	}
+
+	victim, ok := cache.recency.oldest()
+	if !ok || cache.admission.Admit(key, victim) {
+		return true
+	}
+
+	cache.stats.rejectedAdmissions.Add(1)
+	return false
+}
+
+// EvictionCandidates returns, without removing them, the keys of the next n
+// records the cache would remove, in removal order. Size-bounded caches
+// remove their least recently used records first; otherwise records are
+// removed in order of expiry, so expired records come first.
+func (cache *Cache[K, V]) EvictionCandidates(n int) []K {
+	if n <= 0 {
+		return nil
+	}
+
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	keys := make([]K, 0, len(cache.store))
+	if cache.recency != nil {
+		for element := cache.recency.order.Back(); element != nil && len(keys) < n; element = element.Prev() {
+			keys = append(keys, element.Value.(K))
+		}
+		return keys
+	}
+
+	for key := range cache.store {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return cache.store[keys[i]].expiresAt < cache.store[keys[j]].expiresAt
+	})
+
+	if n < len(keys) {
+		keys = keys[:n]
+	}
+	return keys
+}
@@ -0,0 +1,158 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithMaxEntries(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithMaxEntries[int, string](2))
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Set("3", time.Hour)
+
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+	_, ok3 := cache.Get(3)
+
+	assert.False(t, ok1)
+	assert.True(t, ok2)
+	assert.True(t, ok3)
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, uint64(1), cache.Stats().Evicted)
+}
+
+func TestCache_WithMaxEntries_recency(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithMaxEntries[int, string](2))
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Get(1)
+	_, _ = cache.GetOrFetch(3, time.Hour)
+
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+
+	assert.True(t, ok1)
+	assert.False(t, ok2)
+}
+
+func TestCache_EvictionCandidates_maxEntries(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithMaxEntries[int, string](10))
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Set("3", time.Hour)
+	cache.Get(1)
+
+	actual := cache.EvictionCandidates(2)
+
+	assert.Equal(t, []int{2, 3}, actual)
+}
+
+func TestCache_WithMaxEntries_clear(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithMaxEntries[int, string](1))
+	cache.Set("1", time.Hour)
+	cache.Clear()
+	cache.Set("2", time.Hour)
+
+	_, ok := cache.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), cache.Stats().Evicted)
+}
+
+func weighLength(s string) int64 {
+	return int64(len(s))
+}
+
+func TestCache_WithMaxCost(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithMaxCost[int, string](5),
+		WithWeigher[int, string](weighLength),
+	)
+	cache.Set("1", time.Hour)
+	cache.Set("22", time.Hour)
+	cache.Set("333", time.Hour)
+
+	_, ok1 := cache.Get(1)
+	_, ok22 := cache.Get(22)
+	_, ok333 := cache.Get(333)
+
+	assert.False(t, ok1)
+	assert.True(t, ok22)
+	assert.True(t, ok333)
+	assert.Equal(t, int64(5), cache.Stats().Cost)
+	assert.Equal(t, uint64(1), cache.Stats().Evicted)
+}
+
+func TestCache_WithMaxCost_tooLarge(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithMaxCost[int, string](5),
+		WithWeigher[int, string](weighLength),
+	)
+	cache.Set("1", time.Hour)
+	cache.Set("123456", time.Hour)
+
+	_, ok := cache.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, int64(1), cache.Stats().Cost)
+}
+
+func TestCache_WithWeigher_cost(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithWeigher[int, string](weighLength))
+	cache.Set("1", time.Hour)
+	cache.Set("22", time.Hour)
+	cache.Set("22", time.Hour)
+	cache.Delete(1)
+
+	assert.Equal(t, int64(2), cache.Stats().Cost)
+}
+
+func TestCache_WithOnEvict(t *testing.T) {
+	evicted := map[int]string{}
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithMaxEntries[int, string](1),
+		WithOnEvict[int, string](func(k int, v string) { evicted[k] = v }),
+	)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Delete(2)
+
+	assert.Equal(t, map[int]string{1: "1"}, evicted)
+}
+
+func TestCache_WithAccessBuffer(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithMaxEntries[int, string](2),
+		WithAccessBuffer[int, string](2),
+	)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Get(1)
+	cache.Set("3", time.Hour)
+
+	_, buffered := cache.Get(1)
+	cache.GetMany([]int{3, 3})
+	cache.Set("4", time.Hour)
+	_, ok3 := cache.Get(3)
+
+	assert.False(t, buffered)
+	assert.True(t, ok3)
+}
+
+func TestCache_EvictionCandidates_nonPositive(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	assert.Nil(t, cache.EvictionCandidates(0))
+	assert.Nil(t, cache.EvictionCandidates(-1))
+}
@@ -26,17 +26,29 @@ func getKey(v string) int {
 func main() {
 	fetcher := DummyFetcher{}
 
-	// initialize a new cache with int keys and string values
-	cache := cachemem.New[int, string](&fetcher, getKey, time.Minute)
+	// initialize a new cache with int keys and string values, deleting
+	// expired records every minute
+	cache, err := cachemem.New[int, string](
+		&fetcher,
+		getKey,
+		cachemem.WithCleanFrequency[int, string](time.Minute),
+		cachemem.WithJanitor[int, string](),
+	)
+	if err != nil {
+		panic(err)
+	}
 
 	// Set a new record with an expiry of 1 hour
 	cache.Set("123", time.Hour)
 
+	// Set a record that is deleted whenever record 123 is updated or deleted
+	cache.SetWithDeps("456", time.Hour, 123)
+
 	// Get a record from the cache
 	record, ok := cache.Get(1)
 
 	// Get a record from the cache if it exists, otherwise fetch it.
-	record, err := cache.GetOrFetch(2, time.Minute)
+	record, err = cache.GetOrFetch(2, time.Minute)
 
 	// The number of records in the cache
 	cacheLength := cache.Len()
@@ -47,11 +59,8 @@ func main() {
 	// Delete all entries from the cache
 	cache.Clear()
 
-	// Start deleting expired records
-	go cache.StartCleaning()
-
-	// Stop deleting expired records
-	cache.StopCleaning()
+	// Stop deleting expired records and release the cache
+	cache.Close()
 
 	fmt.Println(record)
 	fmt.Println(ok)
@@ -0,0 +1,70 @@
+package cachemem
+
+import "time"
+
+// ExpiredEntry is a record removed from a cache because it expired, see
+// Expired.
+type ExpiredEntry[K comparable, V any] struct {
+	Key   K
+	Value V
+	// ExpiredAt is when the record expired, which may be up to the clean
+	// frequency before it was removed.
+	ExpiredAt time.Time
+}
+
+// Expired returns a channel receiving the records removed by the cleaner
+// because they expired, in no particular order, turning the cache into a
+// queue of items to process once their TTL elapses. Records are sent when
+// the cleaner removes them, up to the clean frequency after they expire.
+// Cached fetch errors are not sent. The channel is buffered with the size set
+// WithExpiredQueue, and records expiring while it is full are dropped and
+// counted in Stats().DroppedExpirations. The channel is closed by Close, and
+// is nil if the cache was not initialized WithExpiredQueue.
+func (cache *Cache[K, V]) Expired() <-chan ExpiredEntry[K, V] {
+	return cache.expiredQueue
+}
+
+func newExpiredQueue[K comparable, V any](size int) chan ExpiredEntry[K, V] {
+	if size <= 0 {
+		return nil
+	}
+	return make(chan ExpiredEntry[K, V], size)
+}
+
+// notifyExpired passes the expired records to the expiry callback and queue,
+// if any.
+func (cache *Cache[K, V]) notifyExpired(expired []ExpiredEntry[K, V]) {
+	for _, e := range expired {
+		cache.expired(e.Key, e.Value)
+		if cache.expiredQueue != nil {
+			cache.queueExpired(e)
+		}
+	}
+}
+
+// queueExpired sends e to the expired queue, unless it is full or the cache
+// is closed.
+func (cache *Cache[K, V]) queueExpired(e ExpiredEntry[K, V]) {
+	cache.expiredMutex.Lock()
+	defer cache.expiredMutex.Unlock()
+
+	if cache.closed.Load() {
+		return
+	}
+	select {
+	case cache.expiredQueue <- e:
+	default:
+		cache.stats.droppedExpirations.Add(1)
+	}
+}
+
+// closeExpiredQueue closes the expired queue, if any. The cache must already
+// be marked as closed.
+func (cache *Cache[K, V]) closeExpiredQueue() {
+	if cache.expiredQueue == nil {
+		return
+	}
+	cache.expiredMutex.Lock()
+	defer cache.expiredMutex.Unlock()
+	close(cache.expiredQueue)
+}
@@ -0,0 +1,64 @@
+package cachemem
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Expired(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithClockResolution[int, string](time.Hour),
+		WithExpiredQueue[int, string](1),
+		WithJanitor[int, string](),
+	)
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.Set("2", time.Minute)
+	cache.Set("3", time.Hour)
+
+	advance(cache, 2*time.Minute)
+	cache.clean()
+	expired := <-cache.Expired()
+
+	assert.Contains(t, []int{1, 2}, expired.Key)
+	assert.Equal(t, strconv.Itoa(expired.Key), expired.Value)
+	assert.Equal(t, cache.epoch.Add(time.Duration(cache.now())-time.Minute), expired.ExpiredAt)
+	assert.Equal(t, uint64(1), cache.Stats().DroppedExpirations)
+	assert.Empty(t, cache.Expired())
+}
+
+func TestCache_Expired_nil(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	assert.Nil(t, cache.Expired())
+}
+
+func TestCache_Expired_closed(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithExpiredQueue[int, string](1),
+		WithCleanFrequency[int, string](time.Millisecond),
+		WithJanitor[int, string](),
+	)
+	cache.Set("1", time.Nanosecond)
+
+	expired, ok := <-cache.Expired()
+	_ = cache.Close()
+	for range cache.Expired() {
+	}
+
+	assert.True(t, ok)
+	assert.Equal(t, 1, expired.Key)
+}
+
+func TestCache_WithExpiredQueue_withoutCleaner(t *testing.T) {
+	_, err := New[int, string](&testFetcher, getKey, WithExpiredQueue[int, string](1))
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
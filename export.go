@@ -0,0 +1,36 @@
+package cachemem
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportCSV writes the records in the cache that have not expired to w as
+// CSV, for loading into analytics tools. The first row is a header naming
+// the columns "key", "expires_at", and then columns. Each following row holds
+// a record's key, formatted with fmt, its expiry in RFC 3339 format, empty if
+// it never expires, and the fields returned by project for the record.
+// Records are written in no particular order, from a copy taken when
+// ExportCSV is called.
+func (cache *Cache[K, V]) ExportCSV(w io.Writer, columns []string, project func(K, V) []string) error {
+	keys, entries := cache.live()
+
+	out := csv.NewWriter(w)
+	if err := out.Write(append([]string{"key", "expires_at"}, columns...)); err != nil {
+		return err
+	}
+	for i, key := range keys {
+		expiresAt := ""
+		if at := cache.expiryTime(entries[i]); !at.IsZero() {
+			expiresAt = at.Format(time.RFC3339Nano)
+		}
+		row := append([]string{fmt.Sprint(key), expiresAt}, project(key, entries[i].value)...)
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
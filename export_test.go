@@ -0,0 +1,24 @@
+package cachemem
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_ExportCSV(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.SetForever("1")
+	cache.Set("2", -time.Hour)
+
+	var out strings.Builder
+	err := cache.ExportCSV(&out, []string{"length"}, func(k int, v string) []string {
+		return []string{strconv.Itoa(len(v))}
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "key,expires_at,length\n1,,1\n", out.String())
+}
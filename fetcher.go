@@ -0,0 +1,167 @@
+package cachemem
+
+import (
+	"errors"
+	"time"
+)
+
+// FetchOneFunc adapts a function fetching a single record into a Fetcher.
+// Its FetchMany fetches each key in turn, leaving out records for which the
+// function returns ErrNotFound and failing on any other error.
+type FetchOneFunc[K comparable, V any] func(K) (V, error)
+
+// FetchOne calls f(key).
+func (f FetchOneFunc[K, V]) FetchOne(key K) (V, error) {
+	return f(key)
+}
+
+// FetchMany calls f for each of keys.
+func (f FetchOneFunc[K, V]) FetchMany(keys []K) ([]V, error) {
+	values := make([]V, 0, len(keys))
+	for _, key := range keys {
+		value, err := f(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// FetchManyFunc adapts a function fetching a batch of records into a
+// Fetcher. Its FetchOne fetches a batch of one key, returning ErrNotFound if
+// the function returns no record.
+type FetchManyFunc[K comparable, V any] func([]K) ([]V, error)
+
+// FetchOne calls f with key alone.
+func (f FetchManyFunc[K, V]) FetchOne(key K) (V, error) {
+	values, err := f([]K{key})
+	if err != nil {
+		var v V
+		return v, err
+	}
+	if len(values) == 0 {
+		var v V
+		return v, ErrNotFound
+	}
+	return values[0], nil
+}
+
+// FetchMany calls f(keys).
+func (f FetchManyFunc[K, V]) FetchMany(keys []K) ([]V, error) {
+	return f(keys)
+}
+
+// FetcherWithTTL may be implemented by fetchers whose records carry their own
+// expiry, such as one derived from an upstream Cache-Control header. When a
+// cache's fetcher implements it, GetOrFetch and FetchMany fetch records with
+// its methods instead, and cache each record for the TTL returned with it
+// rather than the one passed by the caller, unless that TTL is zero. Records
+// returned with a negative TTL have already expired, so they are returned but
+// not cached.
+type FetcherWithTTL[K comparable, V any] interface {
+	FetchOneWithTTL(K) (V, time.Duration, error)
+	FetchManyWithTTL(arrK []K) ([]Expiring[V], error)
+}
+
+// Expiring is a record fetched by a FetcherWithTTL along with its TTL.
+type Expiring[V any] struct {
+	Value V
+	TTL   time.Duration
+}
+
+// MapFetcher fetches records by their key, returning batches of records keyed
+// by their keys so that missing records can be told apart. Adapt it with
+// FromMapFetcher to use it with a cache.
+type MapFetcher[K comparable, V any] interface {
+	FetchOne(K) (V, error)
+	FetchMany(arrK []K) (map[K]V, error)
+}
+
+// FromMapFetcher adapts fetcher into a Fetcher. A cache using the adapted
+// fetcher keys the records fetched by FetchMany by their keys in the map
+// rather than by getKey, and caches ErrNotFound for requested keys missing
+// from the map if initialized WithNegativeTTL or WithNegativeTTLFunc.
+func FromMapFetcher[K comparable, V any](fetcher MapFetcher[K, V]) Fetcher[K, V] {
+	return mapFetcher[K, V]{fetcher: fetcher}
+}
+
+type mapFetcher[K comparable, V any] struct {
+	fetcher MapFetcher[K, V]
+}
+
+func (f mapFetcher[K, V]) FetchOne(key K) (V, error) {
+	return f.fetcher.FetchOne(key)
+}
+
+func (f mapFetcher[K, V]) FetchMany(keys []K) ([]V, error) {
+	fetched, err := f.fetcher.FetchMany(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]V, 0, len(fetched))
+	for _, key := range keys {
+		if value, ok := fetched[key]; ok {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// fetchedBatch holds records fetched together, along with their keys.
+type fetchedBatch[K comparable, V any] struct {
+	keys   []K
+	values []V
+	// expiries holds the TTLs given by a FetcherWithTTL, by key.
+	expiries map[K]time.Duration
+	// keyed reports whether the keys were given by a MapFetcher rather than
+	// by getKey.
+	keyed bool
+}
+
+// fetchKeyed fetches the records with the given keys, along with their keys.
+// Unless the fetcher is a MapFetcher, keys are given by getKey, and records
+// whose key cannot be computed are left out.
+func (cache *Cache[K, V]) fetchKeyed(keys []K) (fetchedBatch[K, V], error) {
+	var batch fetchedBatch[K, V]
+
+	f, keyed := cache.fetcher.(mapFetcher[K, V])
+	if keyed {
+		fetched, err := cache.fetchMap(f.fetcher, keys)
+		if err != nil {
+			return batch, err
+		}
+		batch.keyed = true
+		for _, key := range keys {
+			if value, ok := fetched[key]; ok {
+				batch.keys = append(batch.keys, key)
+				batch.values = append(batch.values, value)
+			}
+		}
+		return batch, nil
+	}
+
+	values, ttls, err := cache.fetchMany(keys)
+	if err != nil {
+		return batch, err
+	}
+	for i, value := range values {
+		key, err := cache.keyOf(value)
+		if err != nil {
+			continue
+		}
+		batch.keys = append(batch.keys, key)
+		batch.values = append(batch.values, value)
+		if ttls != nil && ttls[i] != 0 {
+			if batch.expiries == nil {
+				batch.expiries = map[K]time.Duration{}
+			}
+			batch.expiries[key] = ttls[i]
+		}
+	}
+	return batch, nil
+}
@@ -0,0 +1,127 @@
+package cachemem
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchOneFunc(t *testing.T) {
+	fetcher := FetchOneFunc[int, string](func(i int) (string, error) {
+		if i < 0 {
+			return "", ErrNotFound
+		}
+		return strconv.Itoa(i), nil
+	})
+	cache, _ := New[int, string](fetcher, getKey)
+
+	actual, err := cache.GetOrFetch(1, time.Hour)
+	fetchManyErr := cache.FetchMany([]int{-1, 2}, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.NoError(t, fetchManyErr)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestFetchManyFunc(t *testing.T) {
+	fetcher := FetchManyFunc[int, string](func(keys []int) ([]string, error) {
+		var values []string
+		for _, key := range keys {
+			if key >= 0 {
+				values = append(values, strconv.Itoa(key))
+			}
+		}
+		return values, nil
+	})
+
+	actual, err := fetcher.FetchOne(1)
+	_, missingErr := fetcher.FetchOne(-1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.ErrorIs(t, missingErr, ErrNotFound)
+}
+
+type evenFetcher struct{}
+
+func (evenFetcher) FetchOne(i int) (string, error) {
+	return strconv.Itoa(i), nil
+}
+
+func (evenFetcher) FetchMany(arrI []int) (map[int]string, error) {
+	fetched := map[int]string{}
+	for _, i := range arrI {
+		if i%2 == 0 {
+			fetched[i] = strconv.Itoa(i)
+		}
+	}
+	return fetched, nil
+}
+
+func TestFromMapFetcher(t *testing.T) {
+	cache, _ := New[int, string](
+		FromMapFetcher[int, string](evenFetcher{}),
+		fixedKey,
+		WithNegativeTTL[int, string](time.Hour),
+	)
+
+	err := cache.FetchMany([]int{1, 2}, time.Hour)
+	actual, fetchedErr := cache.GetE(2)
+	_, missingErr := cache.GetE(1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, fetchedErr)
+	assert.Equal(t, "2", actual)
+	assert.ErrorIs(t, missingErr, ErrNotFound)
+	assert.Equal(t, 2, cache.Len())
+}
+
+type shortLivedFetcher struct {
+	TestFetcher
+	ttl time.Duration
+}
+
+func (f *shortLivedFetcher) FetchOneWithTTL(i int) (string, time.Duration, error) {
+	return strconv.Itoa(i), f.ttl, nil
+}
+
+func (f *shortLivedFetcher) FetchManyWithTTL(arrI []int) ([]Expiring[string], error) {
+	var fetched []Expiring[string]
+	for _, i := range arrI {
+		fetched = append(fetched, Expiring[string]{Value: strconv.Itoa(i), TTL: f.ttl})
+	}
+	return fetched, nil
+}
+
+func TestFetcherWithTTL(t *testing.T) {
+	cache, _ := New[int, string](&shortLivedFetcher{ttl: time.Nanosecond}, getKey)
+
+	actual, err := cache.GetOrFetch(1, time.Hour)
+	fetchManyErr := cache.FetchMany([]int{2}, time.Hour)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.NoError(t, fetchManyErr)
+	assert.False(t, ok1)
+	assert.False(t, ok2)
+}
+
+func TestFetcherWithTTL_expired(t *testing.T) {
+	cache, _ := New[int, string](&shortLivedFetcher{ttl: -time.Second}, getKey)
+
+	actual, err := cache.GetOrFetch(1, time.Hour)
+	fetchManyErr := cache.FetchMany([]int{2}, time.Hour)
+	_, ok1 := cache.TTL(1)
+	_, ok2 := cache.TTL(2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.NoError(t, fetchManyErr)
+	assert.False(t, ok1)
+	assert.False(t, ok2)
+}
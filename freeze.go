@@ -0,0 +1,19 @@
+package cachemem
+
+// Freeze makes the cache read-only. While frozen, writes that add or replace
+// records are dropped and counted in Stats().RejectedWrites, while reads are
+// served as usual. Deletions are still applied, so that records polluted by a
+// misbehaving writer can be removed.
+func (cache *Cache[K, V]) Freeze() {
+	cache.frozen.Store(true)
+}
+
+// Unfreeze makes the cache accept writes again after Freeze.
+func (cache *Cache[K, V]) Unfreeze() {
+	cache.frozen.Store(false)
+}
+
+// IsFrozen reports whether the cache is frozen.
+func (cache *Cache[K, V]) IsFrozen() bool {
+	return cache.frozen.Load()
+}
@@ -0,0 +1,51 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Freeze(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	cache.Freeze()
+	cache.Set("2", time.Hour)
+	_, err := cache.GetOrFetch(3, time.Hour)
+
+	value1, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+	_, ok3 := cache.Get(3)
+
+	assert.True(t, cache.IsFrozen())
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value1)
+	assert.True(t, ok1)
+	assert.False(t, ok2)
+	assert.False(t, ok3)
+	assert.Equal(t, uint64(2), cache.Stats().RejectedWrites)
+}
+
+func TestCache_Freeze_delete(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	cache.Freeze()
+	cache.Delete(1)
+
+	_, ok := cache.Get(1)
+	assert.False(t, ok)
+}
+
+func TestCache_Unfreeze(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Freeze()
+	cache.Unfreeze()
+	cache.Set("1", time.Hour)
+
+	_, ok := cache.Get(1)
+	assert.False(t, cache.IsFrozen())
+	assert.True(t, ok)
+}
@@ -0,0 +1,184 @@
+package cachemem
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type groupMember[K comparable, V any] struct {
+	cache    *Cache[K, V]
+	lastUsed time.Time
+}
+
+// groupCreation is a cache being created for a tenant. err is set before done
+// is closed.
+type groupCreation struct {
+	done chan struct{}
+	err  error
+}
+
+// CacheGroup holds a cache per tenant, such as per customer of a
+// multi-tenant service. Caches are created on first use, and closed and
+// dropped once they have not been used for the group's idle TTL, so caches
+// for tenants that have gone away do not accumulate.
+type CacheGroup[T comparable, K comparable, V any] struct {
+	mutex    sync.Mutex
+	newCache func(tenant T) (*Cache[K, V], error)
+	idleTTL  time.Duration
+	caches   map[T]*groupMember[K, V]
+	creating map[T]*groupCreation
+	closed   bool
+	stop     chan struct{}
+}
+
+// NewCacheGroup initializes a new, empty CacheGroup creating caches with
+// newCache, and dropping them once they have not been used for idleTTL. It
+// returns an error wrapping ErrInvalidConfig if idleTTL is not positive.
+func NewCacheGroup[T comparable, K comparable, V any](newCache func(tenant T) (*Cache[K, V], error), idleTTL time.Duration) (*CacheGroup[T, K, V], error) {
+	if idleTTL <= 0 {
+		return nil, fmt.Errorf("%w: non-positive idle TTL", ErrInvalidConfig)
+	}
+
+	group := &CacheGroup[T, K, V]{
+		newCache: newCache,
+		idleTTL:  idleTTL,
+		caches:   map[T]*groupMember[K, V]{},
+		creating: map[T]*groupCreation{},
+		stop:     make(chan struct{}),
+	}
+	go group.dropIdle()
+	return group, nil
+}
+
+// Get returns the cache for tenant, creating it if there is none, and
+// marks it as used. Callers should call Get each time they use the cache,
+// rather than holding on to it, since dropped caches are closed. Get returns
+// ErrClosed if the group is closed, the error returned by newCache, or an
+// error wrapping ErrInvalidConfig if newCache returned neither a cache nor an
+// error.
+// Caches are created without blocking Get for other tenants, and concurrent
+// Gets for a tenant whose cache is being created wait for it.
+func (group *CacheGroup[T, K, V]) Get(tenant T) (*Cache[K, V], error) {
+	group.mutex.Lock()
+	if group.closed {
+		group.mutex.Unlock()
+		return nil, ErrClosed
+	}
+	if member, ok := group.caches[tenant]; ok {
+		member.lastUsed = time.Now()
+		group.mutex.Unlock()
+		return member.cache, nil
+	}
+	if creation, ok := group.creating[tenant]; ok {
+		group.mutex.Unlock()
+		<-creation.done
+		if creation.err != nil {
+			return nil, creation.err
+		}
+		return group.Get(tenant)
+	}
+
+	creation := &groupCreation{done: make(chan struct{})}
+	group.creating[tenant] = creation
+	group.mutex.Unlock()
+
+	return group.create(tenant, creation)
+}
+
+// create creates the cache for tenant outside the group lock, then adds it to
+// the group, unless the group was closed meanwhile. If newCache panics, Gets
+// waiting for the cache retry creating it.
+func (group *CacheGroup[T, K, V]) create(tenant T, creation *groupCreation) (cache *Cache[K, V], err error) {
+	defer func() {
+		group.mutex.Lock()
+		delete(group.creating, tenant)
+		created := err == nil && cache != nil
+		discard := created && group.closed
+		if created && !discard {
+			group.caches[tenant] = &groupMember[K, V]{cache: cache, lastUsed: time.Now()}
+		}
+		group.mutex.Unlock()
+
+		if discard {
+			_ = cache.Close()
+			cache, err = nil, ErrClosed
+		}
+		creation.err = err
+		close(creation.done)
+	}()
+
+	cache, err = group.newCache(tenant)
+	if err == nil && cache == nil {
+		err = fmt.Errorf("%w: newCache returned no cache", ErrInvalidConfig)
+	}
+	return cache, err
+}
+
+// Remove closes and drops the cache for tenant, if there is one.
+func (group *CacheGroup[T, K, V]) Remove(tenant T) {
+	group.mutex.Lock()
+	member, ok := group.caches[tenant]
+	delete(group.caches, tenant)
+	group.mutex.Unlock()
+
+	if ok {
+		_ = member.cache.Close()
+	}
+}
+
+// Len returns the number of caches in the group.
+func (group *CacheGroup[T, K, V]) Len() int {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	return len(group.caches)
+}
+
+// Close closes and drops every cache in the group. After Close, Get returns
+// ErrClosed. Close returns ErrClosed if the group is already closed.
+func (group *CacheGroup[T, K, V]) Close() error {
+	group.mutex.Lock()
+	if group.closed {
+		group.mutex.Unlock()
+		return ErrClosed
+	}
+	group.closed = true
+	close(group.stop)
+	caches := group.caches
+	group.caches = map[T]*groupMember[K, V]{}
+	group.mutex.Unlock()
+
+	for _, member := range caches {
+		_ = member.cache.Close()
+	}
+	return nil
+}
+
+// dropIdle closes and drops the caches not used for the idle TTL, checking
+// every idle TTL until the group is closed.
+func (group *CacheGroup[T, K, V]) dropIdle() {
+	ticker := time.NewTicker(group.idleTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			var idle []*Cache[K, V]
+			group.mutex.Lock()
+			for tenant, member := range group.caches {
+				if time.Since(member.lastUsed) >= group.idleTTL {
+					idle = append(idle, member.cache)
+					delete(group.caches, tenant)
+				}
+			}
+			group.mutex.Unlock()
+
+			for _, cache := range idle {
+				_ = cache.Close()
+			}
+
+		case <-group.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package cachemem
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTenantCache(tenant string) (*Cache[int, string], error) {
+	if tenant == "" {
+		return nil, errors.New("no tenant")
+	}
+	return New[int, string](&testFetcher, getKey)
+}
+
+func TestCacheGroup_Get(t *testing.T) {
+	group, _ := NewCacheGroup[string, int, string](newTenantCache, time.Hour)
+	defer group.Close()
+
+	a, errA := group.Get("a")
+	again, _ := group.Get("a")
+	b, _ := group.Get("b")
+	_, errNone := group.Get("")
+
+	assert.NoError(t, errA)
+	assert.Same(t, a, again)
+	assert.NotSame(t, a, b)
+	assert.Error(t, errNone)
+	assert.Equal(t, 2, group.Len())
+}
+
+func TestCacheGroup_idle(t *testing.T) {
+	group, _ := NewCacheGroup[string, int, string](newTenantCache, 5*time.Millisecond)
+	defer group.Close()
+	cache, _ := group.Get("a")
+
+	assert.Eventually(t, func() bool {
+		return group.Len() == 0
+	}, time.Second, time.Millisecond)
+	assert.True(t, cache.IsClosed())
+}
+
+func TestCacheGroup_Remove(t *testing.T) {
+	group, _ := NewCacheGroup[string, int, string](newTenantCache, time.Hour)
+	defer group.Close()
+	cache, _ := group.Get("a")
+
+	group.Remove("a")
+
+	assert.True(t, cache.IsClosed())
+	assert.Equal(t, 0, group.Len())
+}
+
+func TestCacheGroup_Close(t *testing.T) {
+	group, _ := NewCacheGroup[string, int, string](newTenantCache, time.Hour)
+	cache, _ := group.Get("a")
+
+	err := group.Close()
+	_, errGet := group.Get("a")
+
+	assert.NoError(t, err)
+	assert.True(t, cache.IsClosed())
+	assert.ErrorIs(t, errGet, ErrClosed)
+	assert.ErrorIs(t, group.Close(), ErrClosed)
+}
+
+func TestNewCacheGroup_invalidConfig(t *testing.T) {
+	_, err := NewCacheGroup[string, int, string](newTenantCache, 0)
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestCacheGroup_Get_concurrentCreation(t *testing.T) {
+	release := make(chan struct{})
+	var created atomic.Int32
+	newCache := func(tenant string) (*Cache[int, string], error) {
+		if tenant == "slow" {
+			created.Add(1)
+			<-release
+		}
+		return newTenantCache(tenant)
+	}
+	group, _ := NewCacheGroup[string, int, string](newCache, time.Hour)
+	defer group.Close()
+
+	results := make(chan *Cache[int, string], 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			cache, _ := group.Get("slow")
+			results <- cache
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		return created.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	_, err := group.Get("fast")
+	assert.NoError(t, err)
+
+	close(release)
+	first, second := <-results, <-results
+	assert.NotNil(t, first)
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(1), created.Load())
+}
+
+func TestCacheGroup_Get_nilCache(t *testing.T) {
+	newCache := func(string) (*Cache[int, string], error) {
+		return nil, nil
+	}
+	group, _ := NewCacheGroup[string, int, string](newCache, time.Hour)
+	defer group.Close()
+
+	cache, err := group.Get("a")
+
+	assert.Nil(t, cache)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Equal(t, 0, group.Len())
+}
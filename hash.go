@@ -0,0 +1,31 @@
+package cachemem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+)
+
+// hashKey returns a 64-bit hash of key.
+func hashKey[K comparable](seed maphash.Seed, key K) uint64 {
+	var b [8]byte
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(seed, k)
+	case int:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case int64:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case int32:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case uint:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case uint64:
+		binary.LittleEndian.PutUint64(b[:], k)
+	case uint32:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	default:
+		return maphash.String(seed, fmt.Sprintf("%#v", k))
+	}
+	return maphash.Bytes(seed, b[:])
+}
@@ -0,0 +1,102 @@
+package cachemem
+
+import "time"
+
+// Op is the kind of a recorded mutation.
+type Op string
+
+const (
+	// OpSet is a write of a record.
+	OpSet Op = "set"
+	// OpDelete is a deletion of a record.
+	OpDelete Op = "delete"
+	// OpClear is a deletion of all records.
+	OpClear Op = "clear"
+)
+
+// Reasons recorded for mutations made by the cache itself.
+const (
+	ReasonFetched     = "fetched"
+	ReasonFailed      = "failed"
+	ReasonChanged     = "changed"
+	ReasonDerived     = "derived"
+	ReasonExpired     = "expired"
+	ReasonInvalidated = "invalidated"
+	ReasonEvicted     = "evicted"
+)
+
+// Mutation is a write to the cache recorded in its history.
+type Mutation[K comparable] struct {
+	Key    K
+	Op     Op
+	Reason string
+	At     time.Time
+}
+
+// history is a bounded ring of the most recent mutations.
+type history[K comparable] struct {
+	mutations []Mutation[K]
+	next      int
+	full      bool
+}
+
+func newHistory[K comparable](size int) *history[K] {
+	if size <= 0 {
+		return nil
+	}
+	return &history[K]{mutations: make([]Mutation[K], size)}
+}
+
+func (h *history[K]) record(key K, op Op, reason string) {
+	if h == nil {
+		return
+	}
+
+	h.mutations[h.next] = Mutation[K]{Key: key, Op: op, Reason: reason, At: time.Now()}
+	h.next = (h.next + 1) % len(h.mutations)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// ordered returns the recorded mutations, oldest first.
+func (h *history[K]) ordered() []Mutation[K] {
+	if h == nil {
+		return nil
+	}
+	if !h.full {
+		return h.mutations[:h.next]
+	}
+	return append(h.mutations[h.next:len(h.mutations):len(h.mutations)], h.mutations[:h.next]...)
+}
+
+// SetWithReason writes a new entry to the cache like Set, recording reason
+// against the write in the cache's history.
+func (cache *Cache[K, V]) SetWithReason(value V, expiresIn time.Duration, reason string) {
+	cache.set(value, expiresIn, nil, reason)
+}
+
+// DeleteWithReason deletes a record like Delete, recording reason against
+// the deletion in the cache's history.
+func (cache *Cache[K, V]) DeleteWithReason(key K, reason string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.deleteLocked(key, reason)
+}
+
+// History returns the recorded mutations of the record with key key, oldest
+// first, including any clears of the whole cache. It returns nil unless the
+// cache was initialized WithHistory.
+func (cache *Cache[K, V]) History(key K) []Mutation[K] {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	var mutations []Mutation[K]
+	for _, m := range cache.history.ordered() {
+		if m.Key == key || m.Op == OpClear {
+			mutations = append(mutations, m)
+		}
+	}
+
+	return mutations
+}
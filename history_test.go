@@ -0,0 +1,62 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_History(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithHistory[int, string](10))
+	cache.SetWithReason("1", time.Hour, "backfill")
+	cache.Set("2", time.Hour)
+	cache.DeleteWithReason(1, "bad data")
+
+	actual := cache.History(1)
+
+	require.Len(t, actual, 2)
+	assert.Equal(t, OpSet, actual[0].Op)
+	assert.Equal(t, "backfill", actual[0].Reason)
+	assert.Equal(t, OpDelete, actual[1].Op)
+	assert.Equal(t, "bad data", actual[1].Reason)
+}
+
+func TestCache_History_bounded(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithHistory[int, string](2))
+	cache.SetWithReason("1", time.Hour, "first")
+	cache.SetWithReason("1", time.Hour, "second")
+	cache.SetWithReason("1", time.Hour, "third")
+
+	actual := cache.History(1)
+
+	require.Len(t, actual, 2)
+	assert.Equal(t, "second", actual[0].Reason)
+	assert.Equal(t, "third", actual[1].Reason)
+}
+
+func TestCache_History_internalReasons(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithHistory[int, string](10))
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+	cache.Set("1", time.Hour)
+	_, _ = cache.GetOrFetch(3, time.Hour)
+	cache.Clear()
+
+	history2 := cache.History(2)
+	history3 := cache.History(3)
+
+	require.Len(t, history2, 3)
+	assert.Equal(t, ReasonInvalidated, history2[1].Reason)
+	assert.Equal(t, OpClear, history2[2].Op)
+	require.Len(t, history3, 2)
+	assert.Equal(t, ReasonFetched, history3[0].Reason)
+}
+
+func TestCache_History_disabled(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	assert.Nil(t, cache.History(1))
+}
@@ -0,0 +1,61 @@
+package cachemem
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+)
+
+const hllPrecision = 12
+
+// hyperLogLog estimates the number of distinct keys it has seen, using a
+// fixed 4KiB of memory.
+type hyperLogLog[K comparable] struct {
+	seed      maphash.Seed
+	registers [1 << hllPrecision]uint8
+}
+
+func newHyperLogLog[K comparable](enabled bool) *hyperLogLog[K] {
+	if !enabled {
+		return nil
+	}
+	return &hyperLogLog[K]{seed: maphash.MakeSeed()}
+}
+
+func (h *hyperLogLog[K]) add(key K) {
+	if h == nil {
+		return
+	}
+
+	hash := hashKey(h.seed, key)
+	index := hash >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+func (h *hyperLogLog[K]) estimate() uint64 {
+	if h == nil {
+		return 0
+	}
+
+	m := float64(len(h.registers))
+
+	var sum float64
+	var zeros int
+	for _, register := range h.registers {
+		sum += 1 / float64(uint64(1)<<register)
+		if register == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
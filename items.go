@@ -0,0 +1,88 @@
+package cachemem
+
+import "time"
+
+// live returns the keys and entries of the records in the cache that have
+// not expired and are not cached fetch errors, copied under a single lock
+// acquisition.
+func (cache *Cache[K, V]) live() ([]K, []entry[V]) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	keys := make([]K, 0, len(cache.store))
+	entries := make([]entry[V], 0, len(cache.store))
+	now := cache.now()
+	for key, e := range cache.store {
+		if !e.hasExpired(now) && e.err == nil {
+			keys = append(keys, key)
+			entries = append(entries, e)
+		}
+	}
+	return keys, entries
+}
+
+// Keys returns the keys of the records in the cache that have not expired,
+// in no particular order.
+func (cache *Cache[K, V]) Keys() []K {
+	keys, _ := cache.live()
+	return keys
+}
+
+// Values returns the records in the cache that have not expired, in no
+// particular order.
+func (cache *Cache[K, V]) Values() []V {
+	_, entries := cache.live()
+	values := make([]V, len(entries))
+	for i, e := range entries {
+		values[i] = e.value
+	}
+	return values
+}
+
+// SnapshotEntry is a record in a snapshot of a cache, see Snapshot.
+type SnapshotEntry[V any] struct {
+	Value V
+	// ExpiresAt is when the record expires, or the zero time if it never
+	// expires.
+	ExpiresAt time.Time
+}
+
+// Snapshot is like Items, but also returns when each record expires.
+func (cache *Cache[K, V]) Snapshot() map[K]SnapshotEntry[V] {
+	keys, entries := cache.live()
+	snapshot := make(map[K]SnapshotEntry[V], len(keys))
+	for i, key := range keys {
+		snapshot[key] = SnapshotEntry[V]{
+			Value:     entries[i].value,
+			ExpiresAt: cache.expiryTime(entries[i]),
+		}
+	}
+	return snapshot
+}
+
+// All returns an iterator over the records in the cache that have not
+// expired, in no particular order, which can be ranged over with Go 1.23 and
+// later. It iterates over a copy taken when iteration starts, so the cache
+// is not locked while yield runs and may be used from the loop body.
+func (cache *Cache[K, V]) All() func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		keys, entries := cache.live()
+		for i, key := range keys {
+			if !yield(key, entries[i].value) {
+				return
+			}
+		}
+	}
+}
+
+// Items returns the records in the cache that have not expired, by key. The
+// records are copied under a single lock acquisition, so they are a
+// consistent snapshot of the cache at one point in time.
+func (cache *Cache[K, V]) Items() map[K]V {
+	keys, entries := cache.live()
+	items := make(map[K]V, len(keys))
+	for i, key := range keys {
+		items[key] = entries[i].value
+	}
+	return items
+}
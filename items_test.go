@@ -0,0 +1,74 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newItemsCache() *Cache[int, string] {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.SetForever("2")
+	cache.Set("3", -time.Hour)
+	return cache
+}
+
+func TestCache_Keys(t *testing.T) {
+	cache := newItemsCache()
+
+	assert.ElementsMatch(t, []int{1, 2}, cache.Keys())
+}
+
+func TestCache_Values(t *testing.T) {
+	cache := newItemsCache()
+
+	assert.ElementsMatch(t, []string{"1", "2"}, cache.Values())
+}
+
+func TestCache_Items(t *testing.T) {
+	cache := newItemsCache()
+
+	assert.Equal(t, map[int]string{1: "1", 2: "2"}, cache.Items())
+}
+
+func TestCache_All(t *testing.T) {
+	cache := newItemsCache()
+
+	items := map[int]string{}
+	cache.All()(func(k int, v string) bool {
+		items[k] = v
+		cache.Delete(k)
+		return true
+	})
+
+	assert.Equal(t, map[int]string{1: "1", 2: "2"}, items)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_All_stop(t *testing.T) {
+	cache := newItemsCache()
+
+	yielded := 0
+	cache.All()(func(int, string) bool {
+		yielded++
+		return false
+	})
+
+	assert.Equal(t, 1, yielded)
+}
+
+func TestCache_Snapshot(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithClockResolution[int, string](time.Hour))
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.SetForever("2")
+
+	snapshot := cache.Snapshot()
+
+	assert.Equal(t, map[int]SnapshotEntry[string]{
+		1: {Value: "1", ExpiresAt: cache.epoch.Add(time.Duration(cache.now()) + time.Minute)},
+		2: {Value: "2"},
+	}, snapshot)
+}
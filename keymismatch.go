@@ -0,0 +1,55 @@
+package cachemem
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeyMismatchPolicy decides what GetOrFetch does when the fetcher returns a
+// record whose key, as given by getKey, differs from the requested key.
+type KeyMismatchPolicy int
+
+const (
+	// KeyMismatchIgnore caches the record under its own key only, so later
+	// reads of the requested key miss. It is the default.
+	KeyMismatchIgnore KeyMismatchPolicy = iota
+	// KeyMismatchReject returns a *KeyMismatchError without caching the
+	// record.
+	KeyMismatchReject
+	// KeyMismatchStoreBoth caches the record under both its own key and the
+	// requested key.
+	KeyMismatchStoreBoth
+)
+
+// KeyMismatchError is returned by GetOrFetch under KeyMismatchReject when the
+// fetcher returns a record with a different key from the requested one. It
+// wraps ErrKeyMismatch.
+type KeyMismatchError[K comparable] struct {
+	Requested K
+	Fetched   K
+}
+
+func (err *KeyMismatchError[K]) Error() string {
+	return fmt.Sprintf("%v: requested %v, fetched %v", ErrKeyMismatch, err.Requested, err.Fetched)
+}
+
+func (err *KeyMismatchError[K]) Unwrap() error {
+	return ErrKeyMismatch
+}
+
+// setMismatched caches value, fetched for key but keyed by fetchedKey,
+// according to the cache's key mismatch policy. It returns the entry cached
+// for key, like setFetched.
+func (cache *Cache[K, V]) setMismatched(key, fetchedKey K, value V, expiresIn time.Duration) (entry[V], error) {
+	cache.stats.keyMismatches.Add(1)
+
+	switch cache.keyMismatch {
+	case KeyMismatchReject:
+		return entry[V]{}, &KeyMismatchError[K]{Requested: key, Fetched: fetchedKey}
+	case KeyMismatchStoreBoth:
+		return cache.setFetched([]K{fetchedKey, key}, []V{value, value}, expiresIn, nil)[1], nil
+	default:
+		cache.setFetched([]K{fetchedKey}, []V{value}, expiresIn, nil)
+		return entry[V]{value: value, expiresAt: cache.now()}, nil
+	}
+}
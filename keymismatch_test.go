@@ -0,0 +1,51 @@
+package cachemem
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedKey(string) int {
+	return 1
+}
+
+func TestCache_GetOrFetch_keyMismatch(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, fixedKey)
+
+	actual, err := cache.GetOrFetch(2, time.Hour)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2", actual)
+	assert.True(t, ok1)
+	assert.False(t, ok2)
+	assert.Equal(t, uint64(1), cache.Stats().KeyMismatches)
+}
+
+func TestCache_GetOrFetch_keyMismatchReject(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, fixedKey, WithKeyMismatch[int, string](KeyMismatchReject))
+
+	_, err := cache.GetOrFetch(2, time.Hour)
+
+	var mismatch *KeyMismatchError[int]
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+	assert.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, KeyMismatchError[int]{Requested: 2, Fetched: 1}, *mismatch)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_GetOrFetch_keyMismatchStoreBoth(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, fixedKey, WithKeyMismatch[int, string](KeyMismatchStoreBoth))
+
+	_, err := cache.GetOrFetch(2, time.Hour)
+	actual1, _ := cache.Get(1)
+	actual2, _ := cache.Get(2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2", actual1)
+	assert.Equal(t, "2", actual2)
+}
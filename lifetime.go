@@ -0,0 +1,143 @@
+package cachemem
+
+import (
+	"math"
+	"time"
+)
+
+// GetWithExpiry is like Get, but also returns when the record expires, or
+// the zero time if it never expires. Values that were fetched or derived but
+// not cached are reported as already expired.
+func (cache *Cache[K, V]) GetWithExpiry(key K) (V, time.Time, bool) {
+	e, ok := cache.get(key)
+	if !ok && cache.readThroughTTL > 0 {
+		var err error
+		e, err = cache.fetchEntry(key, cache.readThroughTTL)
+		ok = err == nil
+	}
+	if !ok || e.err != nil {
+		var v V
+		return v, time.Time{}, false
+	}
+	return e.value, cache.expiryTime(e), true
+}
+
+// expiryTime returns when e expires, or the zero time if it never expires.
+func (cache *Cache[K, V]) expiryTime(e entry[V]) time.Time {
+	if e.permanent() {
+		return time.Time{}
+	}
+	return cache.epoch.Add(time.Duration(e.expiresAt))
+}
+
+// TTL returns how long until the record with key key expires, or NoExpiry if
+// it never expires, and reports whether the record exists and has not
+// expired. Unlike Get, it does not count as a read of the record.
+func (cache *Cache[K, V]) TTL(key K) (time.Duration, bool) {
+	now := cache.now()
+	e, ok := cache.lookupAt(key, now)
+	if !ok {
+		return 0, false
+	}
+	if e.permanent() {
+		return NoExpiry, true
+	}
+	return time.Duration(e.expiresAt - now), true
+}
+
+// lookup returns the record with key key, if it exists, has not expired and
+// is not a cached fetch error, without recording the read.
+func (cache *Cache[K, V]) lookup(key K) (entry[V], bool) {
+	return cache.lookupAt(key, cache.now())
+}
+
+// lookupAt is like lookup, but checks expiry as of now.
+func (cache *Cache[K, V]) lookupAt(key K, now int64) (entry[V], bool) {
+	cache.mutex.RLock()
+	e, ok := cache.store[key]
+	cache.mutex.RUnlock()
+	if !ok || e.hasExpired(now) || e.err != nil {
+		return entry[V]{}, false
+	}
+	return e, true
+}
+
+// Touch resets the expiry of the record with key key, if it exists and has
+// not expired, as if it had just been written with the same expiry duration
+// and idle timeout. Touch reports whether the record's expiry was reset.
+func (cache *Cache[K, V]) Touch(key K) bool {
+	return cache.retime(key, func(e *entry[V]) {
+		if e.ttl > 0 {
+			cache.resetExpiry(e)
+		}
+	})
+}
+
+// Extend postpones the expiry of the record with key key, if it exists and
+// has not expired, by d, or brings it forward if d is negative. Records that
+// never expire are unaffected. Extend reports whether the record's expiry
+// was changed.
+func (cache *Cache[K, V]) Extend(key K, d time.Duration) bool {
+	return cache.retime(key, func(e *entry[V]) {
+		e.expiresAt = extended(e.expiresAt, d)
+		if e.idle > 0 {
+			e.limit = extended(e.limit, d)
+		}
+	})
+}
+
+// ExpireAt sets the record with key key, if it exists and has not expired,
+// to expire at t, replacing its expiry duration and any idle timeout.
+// ExpireAt reports whether the record's expiry was changed.
+func (cache *Cache[K, V]) ExpireAt(key K, t time.Time) bool {
+	return cache.retime(key, func(e *entry[V]) {
+		e.expiresAt = cache.deadline(t)
+		e.ttl = e.expiresAt - cache.now()
+		e.idle = 0
+		e.limit = 0
+	})
+}
+
+// retime calls fn to change the expiry of the record with key key, if it
+// exists, has not expired and is not a cached fetch error, reporting whether
+// it did. Changes are dropped while the cache is frozen.
+func (cache *Cache[K, V]) retime(key K, fn func(*entry[V])) bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	e, ok := cache.store[key]
+	if !ok || e.hasExpired(cache.now()) || e.err != nil {
+		return false
+	}
+	if cache.frozen.Load() {
+		cache.stats.rejectedWrites.Add(1)
+		return false
+	}
+
+	wasPermanent, expiresAt := e.permanent(), e.expiresAt
+	fn(&e)
+	cache.capToDepsLocked(&e, cache.dependencies[key])
+	switch {
+	case wasPermanent && !e.permanent():
+		cache.permanent--
+	case !wasPermanent && e.permanent():
+		cache.permanent++
+	}
+	cache.store[key] = e
+	if e.expiresAt < expiresAt {
+		cache.capDependentsLocked(key)
+	}
+	return true
+}
+
+// extended returns the deadline at moved by d, saturating rather than
+// overflowing. Permanent deadlines are returned unchanged.
+func extended(at int64, d time.Duration) int64 {
+	if at == math.MaxInt64 {
+		return at
+	}
+	if d > 0 && int64(d) > math.MaxInt64-at {
+		return math.MaxInt64
+	}
+	return at + int64(d)
+}
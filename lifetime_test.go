@@ -0,0 +1,184 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newLifetimeCache() *Cache[int, string] {
+	cache, _ := New[int, string](&testFetcher, getKey, WithClockResolution[int, string](time.Hour))
+	return cache
+}
+
+func TestCache_Touch(t *testing.T) {
+	cache := newLifetimeCache()
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+
+	advance(cache, 40*time.Second)
+	touched := cache.Touch(1)
+	advance(cache, 40*time.Second)
+	_, ok := cache.Get(1)
+
+	assert.True(t, touched)
+	assert.True(t, ok)
+	assert.False(t, cache.Touch(2))
+}
+
+func TestCache_Extend(t *testing.T) {
+	cache := newLifetimeCache()
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.SetForever("2")
+
+	extended := cache.Extend(1, time.Minute)
+	advance(cache, 90*time.Second)
+	_, ok1 := cache.Get(1)
+	cache.Extend(2, -time.Hour)
+	_, ok2 := cache.Get(2)
+
+	assert.True(t, extended)
+	assert.True(t, ok1)
+	assert.True(t, ok2)
+	assert.Equal(t, 1, cache.Stats().Permanent)
+}
+
+func TestCache_ExpireAt(t *testing.T) {
+	cache := newLifetimeCache()
+	defer cache.Close()
+	cache.SetForever("1")
+	cache.Set("2", time.Hour)
+
+	cache.ExpireAt(1, cache.epoch.Add(time.Duration(cache.now())+time.Minute))
+	cache.ExpireAt(2, cache.epoch.Add(time.Duration(cache.now())+time.Minute))
+	advance(cache, 2*time.Minute)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+
+	assert.False(t, ok1)
+	assert.False(t, ok2)
+	assert.Equal(t, 0, cache.Stats().Permanent)
+}
+
+func TestCache_GetWithExpiry(t *testing.T) {
+	cache := newLifetimeCache()
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.SetForever("2")
+
+	value, expiresAt, ok := cache.GetWithExpiry(1)
+	_, never, okForever := cache.GetWithExpiry(2)
+	_, _, okMissing := cache.GetWithExpiry(3)
+
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, cache.epoch.Add(time.Duration(cache.now())+time.Minute), expiresAt)
+	assert.True(t, okForever)
+	assert.True(t, never.IsZero())
+	assert.False(t, okMissing)
+}
+
+func TestCache_GetWithExpiry_readThrough(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithReadThrough[int, string](time.Minute))
+
+	value, expiresAt, ok := cache.GetWithExpiry(5)
+
+	assert.True(t, ok)
+	assert.Equal(t, "5", value)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+}
+
+func TestCache_GetWithExpiry_readThroughFrozen(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithReadThrough[int, string](time.Minute))
+	cache.Freeze()
+
+	value, expiresAt, ok := cache.GetWithExpiry(5)
+	_, cached := cache.TTL(5)
+
+	assert.True(t, ok)
+	assert.Equal(t, "5", value)
+	assert.False(t, expiresAt.IsZero())
+	assert.False(t, expiresAt.After(time.Now()))
+	assert.False(t, cached)
+}
+
+func TestCache_TTL(t *testing.T) {
+	cache := newLifetimeCache()
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.SetForever("2")
+
+	advance(cache, 20*time.Second)
+	ttl, ok := cache.TTL(1)
+	forever, _ := cache.TTL(2)
+	_, okMissing := cache.TTL(3)
+
+	assert.True(t, ok)
+	assert.Equal(t, 40*time.Second, ttl)
+	assert.Equal(t, NoExpiry, forever)
+	assert.False(t, okMissing)
+	assert.Equal(t, uint64(0), cache.Stats().Hits)
+}
+
+func TestCache_TTL_expiring(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", 50*time.Microsecond)
+
+	for {
+		ttl, ok := cache.TTL(1)
+		if !ok {
+			break
+		}
+		assert.Positive(t, ttl)
+	}
+}
+
+func TestCache_Peek(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithClockResolution[int, string](time.Hour),
+		WithIdleTTL[int, string](time.Minute),
+		WithMaxEntries[int, string](2),
+	)
+	defer cache.Close()
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+
+	advance(cache, 40*time.Second)
+	value, ok := cache.Peek(1)
+	_, okMissing := cache.Peek(3)
+	_, _ = cache.Peek(2)
+	cache.Set("3", time.Hour)
+	_, okEvicted := cache.Peek(1)
+	advance(cache, 21*time.Second)
+	_, okIdle := cache.Peek(2)
+	stats := cache.Stats()
+
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+	assert.False(t, okMissing)
+	assert.False(t, okEvicted)
+	assert.False(t, okIdle)
+	assert.Equal(t, uint64(0), stats.Hits+stats.Misses)
+}
+
+func TestCache_Has(t *testing.T) {
+	cache := newLifetimeCache()
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+	cache.SetForever("2")
+	cache.Set("3", time.Second)
+
+	advance(cache, 2*time.Second)
+
+	assert.True(t, cache.Has(1))
+	assert.True(t, cache.Has(2))
+	assert.False(t, cache.Has(3))
+	assert.False(t, cache.Has(4))
+	assert.Equal(t, uint64(0), cache.Stats().Hits)
+}
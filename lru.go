@@ -0,0 +1,57 @@
+package cachemem
+
+import "container/list"
+
+// lru tracks keys in least recently used order.
+type lru[K comparable] struct {
+	order    *list.List
+	elements map[K]*list.Element
+}
+
+// newRecency returns an lru for tracking the recency of records in a
+// bounded cache, or nil if the cache is unbounded.
+func newRecency[K comparable](bounded bool) *lru[K] {
+	if !bounded {
+		return nil
+	}
+	return newLRU[K]()
+}
+
+func newLRU[K comparable]() *lru[K] {
+	return &lru[K]{
+		order:    list.New(),
+		elements: map[K]*list.Element{},
+	}
+}
+
+// touch marks key as the most recently used key, adding it if necessary.
+// It reports whether key was already tracked.
+func (l *lru[K]) touch(key K) bool {
+	if element, ok := l.elements[key]; ok {
+		l.order.MoveToFront(element)
+		return true
+	}
+	l.elements[key] = l.order.PushFront(key)
+	return false
+}
+
+func (l *lru[K]) remove(key K) {
+	if element, ok := l.elements[key]; ok {
+		l.order.Remove(element)
+		delete(l.elements, key)
+	}
+}
+
+// oldest returns the least recently used key.
+func (l *lru[K]) oldest() (K, bool) {
+	element := l.order.Back()
+	if element == nil {
+		var zero K
+		return zero, false
+	}
+	return element.Value.(K), true
+}
+
+func (l *lru[K]) len() int {
+	return len(l.elements)
+}
@@ -0,0 +1,468 @@
+package cachemem
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option configures optional behavior of a Cache.
+type Option[K comparable, V any] func(*Config[K, V])
+
+// Config holds the configuration of a Cache, as set by the options given to
+// New. Custom options may set its fields directly.
+type Config[K comparable, V any] struct {
+	CleanFrequency     time.Duration
+	Janitor            bool
+	Quiescence         time.Duration
+	InitialCapacity    int
+	MaxEntries         int
+	MaxCost            int64
+	Weigher            func(V) int64
+	Admission          Admission[K]
+	OnEvict            func(K, V)
+	OnExpire           func(K, V)
+	ExpiredQueue       int
+	HistorySize        int
+	ShadowCapacity     int
+	BypassFraction     float64
+	ValidationFraction float64
+	Equal              func(a, b V) bool
+	NegativeTTL        func(error) time.Duration
+	ExpiryFunc         func(V) (time.Time, bool)
+	ReadThrough        bool
+	ReadThroughTTL     time.Duration
+	KeyMismatch        KeyMismatchPolicy
+	StrictFetchMany    bool
+	ZeroValue          ZeroValuePolicy
+	PanicHandler       func(*PanicError)
+	BatchWindow        time.Duration
+	ClockResolution    time.Duration
+	AccessBuffer       int
+	DefaultTTL         time.Duration
+	PrefetchWorkers    int
+	TTLJitter          float64
+	PredictorCapacity  int
+	SlidingExpiration  bool
+	MaxLifetime        time.Duration
+	IdleTTL            time.Duration
+	UniqueKeys         bool
+
+	index keyIndex[K]
+}
+
+// DefaultCleanFrequency is the frequency at which StartCleaning removes
+// expired records from caches initialized without WithCleanFrequency.
+const DefaultCleanFrequency = time.Minute
+
+func newConfig[K comparable, V any](opts []Option[K, V]) Config[K, V] {
+	var cfg Config[K, V]
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Validate reports whether cfg is a valid configuration, returning an error
+// wrapping ErrInvalidConfig if not.
+func (cfg *Config[K, V]) Validate() error {
+	switch {
+	case cfg.CleanFrequency < 0:
+		return fmt.Errorf("%w: negative clean frequency", ErrInvalidConfig)
+	case cfg.Quiescence < 0:
+		return fmt.Errorf("%w: negative quiescence", ErrInvalidConfig)
+	case cfg.InitialCapacity < 0:
+		return fmt.Errorf("%w: negative initial capacity", ErrInvalidConfig)
+	case cfg.MaxEntries < 0:
+		return fmt.Errorf("%w: negative max entries", ErrInvalidConfig)
+	case cfg.MaxCost < 0:
+		return fmt.Errorf("%w: negative max cost", ErrInvalidConfig)
+	case cfg.Admission != nil && cfg.MaxEntries == 0 && cfg.MaxCost == 0:
+		return fmt.Errorf("%w: admission requires max entries or max cost", ErrInvalidConfig)
+	case cfg.OnEvict != nil && cfg.MaxEntries == 0 && cfg.MaxCost == 0:
+		return fmt.Errorf("%w: eviction callback requires max entries or max cost", ErrInvalidConfig)
+	case cfg.HistorySize < 0:
+		return fmt.Errorf("%w: negative history size", ErrInvalidConfig)
+	case cfg.ShadowCapacity < 0:
+		return fmt.Errorf("%w: negative shadow capacity", ErrInvalidConfig)
+	case cfg.BypassFraction < 0 || cfg.BypassFraction > 1:
+		return fmt.Errorf("%w: bypass fraction must be between 0 and 1", ErrInvalidConfig)
+	case cfg.ValidationFraction < 0 || cfg.ValidationFraction > 1:
+		return fmt.Errorf("%w: validation fraction must be between 0 and 1", ErrInvalidConfig)
+	case cfg.ValidationFraction > 0 && cfg.Equal == nil:
+		return fmt.Errorf("%w: validation requires an equality function", ErrInvalidConfig)
+	case cfg.TTLJitter < 0 || cfg.TTLJitter > 1:
+		return fmt.Errorf("%w: TTL jitter must be between 0 and 1", ErrInvalidConfig)
+	case cfg.SlidingExpiration && cfg.MaxLifetime <= 0:
+		return fmt.Errorf("%w: non-positive max lifetime", ErrInvalidConfig)
+	case cfg.OnExpire != nil && cfg.CleanFrequency == 0 && !cfg.Janitor:
+		return fmt.Errorf("%w: expiry callback requires a clean frequency or janitor", ErrInvalidConfig)
+	case cfg.ExpiredQueue < 0:
+		return fmt.Errorf("%w: negative expired queue size", ErrInvalidConfig)
+	case cfg.ExpiredQueue > 0 && cfg.CleanFrequency == 0 && !cfg.Janitor:
+		return fmt.Errorf("%w: expired queue requires a clean frequency or janitor", ErrInvalidConfig)
+	case cfg.IdleTTL < 0:
+		return fmt.Errorf("%w: negative idle TTL", ErrInvalidConfig)
+	case cfg.IdleTTL > 0 && cfg.SlidingExpiration:
+		return fmt.Errorf("%w: idle TTL and sliding expiration are mutually exclusive", ErrInvalidConfig)
+	case cfg.PredictorCapacity < 0:
+		return fmt.Errorf("%w: negative predictor capacity", ErrInvalidConfig)
+	case cfg.PredictorCapacity > 0 && cfg.DefaultTTL == 0:
+		return fmt.Errorf("%w: predictive prefetch requires a default TTL", ErrInvalidConfig)
+	case cfg.PrefetchWorkers < 0:
+		return fmt.Errorf("%w: negative prefetch workers", ErrInvalidConfig)
+	case cfg.DefaultTTL < 0:
+		return fmt.Errorf("%w: negative default TTL", ErrInvalidConfig)
+	case cfg.AccessBuffer < 0:
+		return fmt.Errorf("%w: negative access buffer size", ErrInvalidConfig)
+	case cfg.ClockResolution < 0:
+		return fmt.Errorf("%w: negative clock resolution", ErrInvalidConfig)
+	case cfg.BatchWindow < 0:
+		return fmt.Errorf("%w: negative batch window", ErrInvalidConfig)
+	case cfg.ReadThrough && cfg.ReadThroughTTL <= 0:
+		return fmt.Errorf("%w: read-through TTL must be positive", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// WithCleanFrequency sets the frequency at which the cleaner removes expired
+// records from the cache, once started by StartCleaning or WithJanitor. It
+// defaults to DefaultCleanFrequency.
+func WithCleanFrequency[K comparable, V any](freq time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.CleanFrequency = freq
+	}
+}
+
+// WithJanitor makes New start cleaning the cache in a janitor goroutine, as
+// if by StartCleaning, until the cache is closed or StopCleaning is called.
+// The janitor is running when New returns. A cache with a janitor must be
+// closed with Close once no longer needed, or the janitor leaks.
+func WithJanitor[K comparable, V any]() Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.Janitor = true
+	}
+}
+
+// WithQuiescence pauses the cleaner once the cache has not been read or
+// written for d, resuming it when the cache is next used, so that many idle
+// caches do not spend CPU on empty sweeps. Expired records are not removed
+// while the cleaner is paused.
+func WithQuiescence[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.Quiescence = d
+	}
+}
+
+// WithOnEvict sets a function called with each record evicted to respect
+// WithMaxEntries or WithMaxCost. It is called with the cache locked, so it
+// must not use the cache.
+func WithOnEvict[K comparable, V any](onEvict func(K, V)) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.OnEvict = onEvict
+	}
+}
+
+// WithOnExpire sets a function called with each record removed by the
+// cleaner because it expired, so it is called up to the clean frequency
+// after the record expires, and only while the cache is being cleaned. It
+// requires WithCleanFrequency or WithJanitor. While the cleaner is paused
+// WithQuiescence, expired records are not removed, so the function is not
+// called until the cache is next used. It is not called for records that are
+// evicted, deleted or overwritten, or for cached fetch errors. It is called
+// without the cache locked, so it may use the cache.
+func WithOnExpire[K comparable, V any](onExpire func(K, V)) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.OnExpire = onExpire
+	}
+}
+
+// WithExpiredQueue sends the records removed by the cleaner because they
+// expired to the channel returned by Expired, buffering up to size of them.
+// It requires WithCleanFrequency or WithJanitor, and records are only sent
+// while the cache is being cleaned.
+func WithExpiredQueue[K comparable, V any](size int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.ExpiredQueue = size
+	}
+}
+
+// WithInitialCapacity preallocates space for n records, avoiding repeated
+// growth of the cache's internal map while it is first filled.
+func WithInitialCapacity[K comparable, V any](n int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.InitialCapacity = n
+	}
+}
+
+// WithMaxEntries bounds the cache to n records. When a write would exceed
+// the bound, the least recently used records are evicted, where records are
+// used by being written or read with Get, GetMany or GetOrFetch.
+func WithMaxEntries[K comparable, V any](n int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.MaxEntries = n
+	}
+}
+
+// WithMaxCost bounds the total cost of the records in the cache to maxCost.
+// When a write would exceed the bound, the least recently used records are
+// evicted, like WithMaxEntries. The cost of a record is given by the function
+// set WithWeigher, or is 1 by default.
+func WithMaxCost[K comparable, V any](maxCost int64) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.MaxCost = maxCost
+	}
+}
+
+// WithWeigher sets the function giving the cost of each record, such as its
+// approximate size in bytes, for use WithMaxCost.
+func WithWeigher[K comparable, V any](weigher func(V) int64) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.Weigher = weigher
+	}
+}
+
+// WithAdmission sets the policy deciding whether records fetched by
+// GetOrFetch or FetchMany into a full cache are admitted, evicting the least
+// recently used record, or discarded. It requires WithMaxEntries or
+// WithMaxCost, and applies whenever admitting a record would exceed either
+// bound. See NewTinyLFU.
+func WithAdmission[K comparable, V any](admission Admission[K]) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.Admission = admission
+	}
+}
+
+// WithHistory records the last size mutations of the cache, so that they can
+// be inspected with History.
+func WithHistory[K comparable, V any](size int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.HistorySize = size
+	}
+}
+
+// WithShadow evaluates an LRU cache holding at most capacity records against
+// the reads made on the cache, as if misses were fetched into it. The shadow
+// cache never serves reads; its hypothetical hit ratio is reported by
+// Stats().ShadowHitRatio, so a capacity can be evaluated safely in
+// production.
+func WithShadow[K comparable, V any](capacity int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.ShadowCapacity = capacity
+	}
+}
+
+// WithBypassFraction sends fraction f of GetOrFetch calls straight to the
+// fetcher, caching the fetched record as usual. Whether the cache would have
+// served the call, and whether the cached record differed from the fetched
+// one (see WithEqual), is reported by Stats.
+func WithBypassFraction[K comparable, V any](f float64) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.BypassFraction = f
+	}
+}
+
+// WithValidationFraction also fetches the record for fraction f of
+// GetOrFetch cache hits, and compares it with the cached record using the
+// function configured WithEqual, which is required. Mismatches are counted in
+// Stats, and the cached record is served and left unchanged either way.
+func WithValidationFraction[K comparable, V any](f float64) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.ValidationFraction = f
+	}
+}
+
+// WithEqual sets the function used to compare cached records with freshly
+// fetched ones.
+func WithEqual[K comparable, V any](equal func(a, b V) bool) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.Equal = equal
+	}
+}
+
+// WithNegativeTTL caches fetch errors returned by GetOrFetch for ttl, so
+// that failing records are not fetched again until ttl elapses. Reads of
+// cached errors are counted in Stats().NegativeHits.
+func WithNegativeTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return WithNegativeTTLFunc[K, V](func(error) time.Duration {
+		return ttl
+	})
+}
+
+// WithNegativeTTLFunc caches fetch errors returned by GetOrFetch for the
+// duration returned by classify for each error, so that different classes
+// of error can be cached for different lengths of time. Errors for which
+// classify returns zero or less are not cached.
+func WithNegativeTTLFunc[K comparable, V any](classify func(error) time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.NegativeTTL = classify
+	}
+}
+
+// WithExpiryFunc recomputes the expiry of records updated in place by Update
+// from their new value, for values that carry their own expiry. If fn returns
+// false, the record keeps its current expiry.
+func WithExpiryFunc[K comparable, V any](fn func(V) (time.Time, bool)) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.ExpiryFunc = fn
+	}
+}
+
+// WithReadThrough makes Get fetch records that are missing from the cache,
+// like GetOrFetch, caching them for defaultTTL. Get then reports a fetched
+// record as found, and a failed fetch as not found.
+func WithReadThrough[K comparable, V any](defaultTTL time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.ReadThrough = true
+		cfg.ReadThroughTTL = defaultTTL
+	}
+}
+
+// WithKeyMismatch sets what GetOrFetch does when the fetcher returns a record
+// keyed differently from the requested key. It defaults to KeyMismatchIgnore.
+func WithKeyMismatch[K comparable, V any](policy KeyMismatchPolicy) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.KeyMismatch = policy
+	}
+}
+
+// WithStrictFetchMany makes FetchMany discard fetched records whose keys were
+// not requested from the fetcher, counting them in
+// Stats().UnrequestedFetches, so that backends returning extra records
+// cannot fill the cache.
+func WithStrictFetchMany[K comparable, V any]() Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.StrictFetchMany = true
+	}
+}
+
+// WithZeroValue sets what the cache does when the fetcher returns the zero
+// value with a nil error. It defaults to ZeroValueCache. Under the other
+// policies, FetchMany discards zero values.
+func WithZeroValue[K comparable, V any](policy ZeroValuePolicy) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.ZeroValue = policy
+	}
+}
+
+// WithPanicHandler recovers panics in the fetcher, getKey, and the functions
+// set WithWeigher, WithOnEvict and WithOnExpire, passing them to handler
+// instead of unwinding through the cache while it may be locked. Operations
+// affected by a recovered panic behave as follows: fetches return the
+// *PanicError, records whose key cannot be computed are not written, records
+// whose weigher panics have a cost of 1, and panicking eviction and expiry
+// callbacks are skipped. handler is called with the cache locked when
+// recovering from the weigher or eviction callback, so it must not use the
+// cache.
+func WithPanicHandler[K comparable, V any](handler func(*PanicError)) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.PanicHandler = handler
+	}
+}
+
+// WithBatchWindow batches the fetches made by GetOrFetch for records missing
+// from the cache: the keys missed by concurrent calls within window of the
+// first miss are fetched with a single call to FetchMany. Keys for which
+// FetchMany returns no record fail with ErrNotFound.
+func WithBatchWindow[K comparable, V any](window time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.BatchWindow = window
+	}
+}
+
+// WithClockResolution makes the cache read the time for expiry from a clock
+// updated every resolution by a background goroutine, rather than calling
+// time.Now on every operation. Records may then be served for up to
+// resolution after they expire. The cache must be closed with Close once no
+// longer needed, or the goroutine leaks.
+func WithClockResolution[K comparable, V any](resolution time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.ClockResolution = resolution
+	}
+}
+
+// WithAccessBuffer makes reads of a cache bounded WithMaxEntries or
+// WithMaxCost record the records they use in a buffer of size records, which
+// is applied to the cache's recency order once full, so that reads do not
+// take the cache's write lock. Eviction then only accounts for reads once
+// their buffer has been applied.
+func WithAccessBuffer[K comparable, V any](size int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.AccessBuffer = size
+	}
+}
+
+// WithDefaultTTL sets the expiry duration used by SetDefault,
+// GetOrFetchDefault and FetchManyDefault. Without it, records written by
+// them never expire.
+func WithDefaultTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.DefaultTTL = ttl
+	}
+}
+
+// WithPrefetchWorkers sets the number of prefetches started by Prefetch that
+// may run at once. It defaults to DefaultPrefetchWorkers.
+func WithPrefetchWorkers[K comparable, V any](n int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.PrefetchWorkers = n
+	}
+}
+
+// WithTTLJitter shortens each record's expiry duration by a random fraction
+// of up to f, so that records cached together, such as by FetchMany, expire
+// spread over time rather than all at once. Records are never cached for
+// longer than requested, and records that never expire are unaffected.
+func WithTTLJitter[K comparable, V any](f float64) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.TTLJitter = f
+	}
+}
+
+// WithPredictivePrefetch learns which key is usually read after each key,
+// and on a cache hit prefetches the key usually read next, see Prefetch,
+// caching it for the duration set WithDefaultTTL, which is required. At most
+// capacity keys are tracked. Stats().PredictedHits against
+// Stats().PredictedPrefetches shows how useful the predictions are.
+func WithPredictivePrefetch[K comparable, V any](capacity int) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.PredictorCapacity = capacity
+	}
+}
+
+// WithSlidingExpiration extends a record's expiry each time it is read, to
+// its expiry duration from the read, like a session that times out when
+// idle. Records still expire maxLifetime after they were written, however
+// often they are read; NoExpiry leaves their lifetime unbounded. Records
+// that never expire are unaffected.
+func WithSlidingExpiration[K comparable, V any](maxLifetime time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.SlidingExpiration = true
+		cfg.MaxLifetime = maxLifetime
+	}
+}
+
+// WithIdleTTL expires records that have not been read for idle, as well as
+// after their expiry duration regardless of reads. Unlike
+// WithSlidingExpiration, every record has the same idle timeout, unless it is
+// written by SetWithIdleTTL.
+func WithIdleTTL[K comparable, V any](idle time.Duration) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.IdleTTL = idle
+	}
+}
+
+// WithUniqueKeys estimates the number of distinct keys written to the cache,
+// reported in Stats().UniqueKeys, using a fixed 4KiB of memory. Each write
+// hashes its key; keys that are not strings or integers are formatted with
+// fmt to be hashed, which is comparatively slow.
+func WithUniqueKeys[K comparable, V any]() Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.UniqueKeys = true
+	}
+}
+
+func withIndex[K comparable, V any](index keyIndex[K]) Option[K, V] {
+	return func(cfg *Config[K, V]) {
+		cfg.index = index
+	}
+}
@@ -0,0 +1,32 @@
+package cachemem
+
+import "time"
+
+// SetTTLOverride makes every subsequent write of the record with key key
+// expire after ttl, regardless of the expiry requested by the writer. This
+// allows the TTL of problematic records to be changed without changing the
+// code that writes them. The record's current expiry is left unchanged.
+func (cache *Cache[K, V]) SetTTLOverride(key K, ttl time.Duration) {
+	cache.mutex.Lock()
+	cache.ttlOverrides[key] = ttl
+	cache.mutex.Unlock()
+}
+
+// RemoveTTLOverride removes the TTL override for key, if any.
+func (cache *Cache[K, V]) RemoveTTLOverride(key K) {
+	cache.mutex.Lock()
+	delete(cache.ttlOverrides, key)
+	cache.mutex.Unlock()
+}
+
+// TTLOverrides returns the current TTL overrides by key.
+func (cache *Cache[K, V]) TTLOverrides() map[K]time.Duration {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	overrides := make(map[K]time.Duration, len(cache.ttlOverrides))
+	for key, ttl := range cache.ttlOverrides {
+		overrides[key] = ttl
+	}
+	return overrides
+}
@@ -0,0 +1,36 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetTTLOverride(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.SetTTLOverride(1, time.Nanosecond)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+
+	time.Sleep(10 * time.Nanosecond)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+
+	assert.False(t, ok1)
+	assert.True(t, ok2)
+	assert.Equal(t, map[int]time.Duration{1: time.Nanosecond}, cache.TTLOverrides())
+}
+
+func TestCache_RemoveTTLOverride(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.SetTTLOverride(1, time.Nanosecond)
+	cache.RemoveTTLOverride(1)
+	cache.Set("1", time.Hour)
+
+	time.Sleep(10 * time.Nanosecond)
+	_, ok := cache.Get(1)
+
+	assert.True(t, ok)
+	assert.Empty(t, cache.TTLOverrides())
+}
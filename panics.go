@@ -0,0 +1,125 @@
+package cachemem
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// PanicError describes a panic recovered from user-supplied code called by
+// the cache, such as the fetcher, getKey, or the functions set WithWeigher,
+// WithOnEvict and WithOnExpire. Panics are only recovered if the cache was
+// initialized WithPanicHandler.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("cachemem: recovered panic: %v", err.Value)
+}
+
+// recoverPanic recovers a panic, if the cache has a panic handler, reporting
+// it to the handler and storing it in *err if err is not nil. It must be
+// deferred directly.
+func (cache *Cache[K, V]) recoverPanic(err *error) {
+	if cache.panicHandler == nil {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	panicErr := &PanicError{Value: r, Stack: debug.Stack()}
+	cache.panicHandler(panicErr)
+	if err != nil {
+		*err = panicErr
+	}
+}
+
+// keyOf returns the key of value.
+func (cache *Cache[K, V]) keyOf(value V) (key K, err error) {
+	defer cache.recoverPanic(&err)
+	return cache.getKey(value), nil
+}
+
+// keysOf returns the keys of values, along with the values themselves. Values
+// whose key could not be computed are left out of both.
+func (cache *Cache[K, V]) keysOf(values []V) ([]K, []V) {
+	keys := make([]K, 0, len(values))
+	keyed := values[:0:0]
+	for _, value := range values {
+		if key, err := cache.keyOf(value); err == nil {
+			keys = append(keys, key)
+			keyed = append(keyed, value)
+		}
+	}
+	return keys, keyed
+}
+
+// fetchOne fetches the record with key key from the fetcher, along with its
+// TTL if the fetcher is a FetcherWithTTL.
+func (cache *Cache[K, V]) fetchOne(key K) (value V, ttl time.Duration, err error) {
+	if cache.fetcher == nil {
+		return value, 0, ErrNoFetcher
+	}
+	defer cache.recoverPanic(&err)
+	if f, ok := cache.fetcher.(FetcherWithTTL[K, V]); ok {
+		return f.FetchOneWithTTL(key)
+	}
+	value, err = cache.fetcher.FetchOne(key)
+	return value, 0, err
+}
+
+// fetchMany fetches the records with the given keys from the fetcher. If the
+// fetcher is a FetcherWithTTL, it also returns the TTL of each record.
+func (cache *Cache[K, V]) fetchMany(keys []K) (values []V, ttls []time.Duration, err error) {
+	if cache.fetcher == nil {
+		return nil, nil, ErrNoFetcher
+	}
+	defer cache.recoverPanic(&err)
+	f, ok := cache.fetcher.(FetcherWithTTL[K, V])
+	if !ok {
+		values, err = cache.fetcher.FetchMany(keys)
+		return values, nil, err
+	}
+
+	fetched, err := f.FetchManyWithTTL(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	values = make([]V, len(fetched))
+	ttls = make([]time.Duration, len(fetched))
+	for i, e := range fetched {
+		values[i] = e.Value
+		ttls[i] = e.TTL
+	}
+	return values, ttls, nil
+}
+
+// fetchMap fetches the records with the given keys from fetcher.
+func (cache *Cache[K, V]) fetchMap(fetcher MapFetcher[K, V], keys []K) (values map[K]V, err error) {
+	defer cache.recoverPanic(&err)
+	return fetcher.FetchMany(keys)
+}
+
+// evicted calls the eviction callback, if any, for the evicted record.
+func (cache *Cache[K, V]) evicted(key K, value V) {
+	if cache.onEvict == nil {
+		return
+	}
+	defer cache.recoverPanic(nil)
+	cache.onEvict(key, value)
+}
+
+// expired calls the expiry callback, if any, for the expired record.
+func (cache *Cache[K, V]) expired(key K, value V) {
+	if cache.onExpire == nil {
+		return
+	}
+	defer cache.recoverPanic(nil)
+	cache.onExpire(key, value)
+}
@@ -0,0 +1,99 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type panickingFetcher struct{}
+
+func (panickingFetcher) FetchOne(int) (string, error) {
+	panic("fetch one")
+}
+
+func (panickingFetcher) FetchMany([]int) ([]string, error) {
+	panic("fetch many")
+}
+
+func TestCache_WithPanicHandler_fetcher(t *testing.T) {
+	var recovered []any
+	cache, _ := New[int, string](
+		panickingFetcher{},
+		getKey,
+		WithPanicHandler[int, string](func(err *PanicError) { recovered = append(recovered, err.Value) }),
+	)
+
+	_, err := cache.GetOrFetch(1, time.Hour)
+	fetchManyErr := cache.FetchMany([]int{2}, time.Hour)
+
+	var panicErr *PanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.ErrorAs(t, fetchManyErr, &panicErr)
+	assert.Equal(t, []any{"fetch one", "fetch many"}, recovered)
+}
+
+func TestCache_WithPanicHandler_getKey(t *testing.T) {
+	var recovered int
+	cache, _ := New[int, string](
+		&testFetcher,
+		func(s string) int {
+			if s == "" {
+				panic("no key")
+			}
+			return getKey(s)
+		},
+		WithPanicHandler[int, string](func(*PanicError) { recovered++ }),
+	)
+
+	cache.Set("", time.Hour)
+	cache.SetMany([]string{"1", ""}, time.Hour)
+
+	assert.Equal(t, 2, recovered)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestCache_WithPanicHandler_onEvict(t *testing.T) {
+	var recovered int
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithMaxEntries[int, string](1),
+		WithOnEvict[int, string](func(int, string) { panic("evicted") }),
+		WithPanicHandler[int, string](func(*PanicError) { recovered++ }),
+	)
+
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	actual, ok := cache.Get(2)
+
+	assert.Equal(t, 1, recovered)
+	assert.True(t, ok)
+	assert.Equal(t, "2", actual)
+}
+
+func TestCache_weigherPanic_withoutHandler(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithMaxCost[int, string](10),
+		WithWeigher[int, string](func(string) int64 { panic("weigher") }),
+	)
+
+	assert.Panics(t, func() { cache.Set("1", time.Hour) })
+	assert.Panics(t, func() { cache.SetMany([]string{"1"}, time.Hour) })
+	assert.Panics(t, func() { _, _ = cache.GetOrFetch(1, time.Hour) })
+
+	done := make(chan struct{})
+	go func() {
+		cache.Get(1)
+		cache.Delete(1)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("cache still locked after recovered panic")
+	}
+}
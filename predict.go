@@ -0,0 +1,85 @@
+package cachemem
+
+import "sync"
+
+// successor is the key predicted to be read after another, with the number
+// of votes for it. Votes are counted with the majority vote algorithm, so a
+// successor that follows its key more often than not is eventually found.
+type successor[K comparable] struct {
+	key   K
+	votes int
+}
+
+// predictor learns which key is usually read after each key, tracking at
+// most capacity keys, and remembers the keys it predicted that have not been
+// read since.
+type predictor[K comparable] struct {
+	mutex      sync.Mutex
+	capacity   int
+	last       K
+	hasLast    bool
+	successors map[K]successor[K]
+	predicted  map[K]struct{}
+}
+
+func newPredictor[K comparable](capacity int) *predictor[K] {
+	if capacity <= 0 {
+		return nil
+	}
+	return &predictor[K]{
+		capacity:   capacity,
+		successors: map[K]successor[K]{},
+		predicted:  map[K]struct{}{},
+	}
+}
+
+// observe records a read of key, reporting whether it was predicted, and
+// returns the key predicted to be read next, if any. Once capacity keys are
+// tracked, the successors of new keys are not learned.
+func (p *predictor[K]) observe(key K) (wasPredicted bool, next K, ok bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, wasPredicted = p.predicted[key]; wasPredicted {
+		delete(p.predicted, key)
+	}
+
+	if p.hasLast && p.last != key {
+		s, tracked := p.successors[p.last]
+		switch {
+		case !tracked && len(p.successors) >= p.capacity:
+		case s.key == key:
+			s.votes++
+			p.successors[p.last] = s
+		case s.votes == 0:
+			p.successors[p.last] = successor[K]{key: key, votes: 1}
+		default:
+			s.votes--
+			p.successors[p.last] = s
+		}
+	}
+	p.last, p.hasLast = key, true
+
+	s, tracked := p.successors[key]
+	if !tracked || s.votes == 0 {
+		return wasPredicted, next, false
+	}
+	if len(p.predicted) < p.capacity {
+		p.predicted[s.key] = struct{}{}
+	}
+	return wasPredicted, s.key, true
+}
+
+// predict learns from a read of key, and on a hit prefetches the key
+// usually read next.
+func (cache *Cache[K, V]) predict(key K, r read) {
+	wasPredicted, next, ok := cache.predictor.observe(key)
+	if wasPredicted && r == readHit {
+		cache.stats.predictedHits.Add(1)
+	}
+	if !ok || r != readHit {
+		return
+	}
+	cache.stats.predictedPrefetches.Add(1)
+	cache.Prefetch(next)
+}
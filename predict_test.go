@@ -0,0 +1,58 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithPredictivePrefetch(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithPredictivePrefetch[int, string](10), WithDefaultTTL[int, string](time.Hour))
+	cache.Set("1", time.Hour)
+
+	_, _ = cache.GetOrFetch(1, time.Hour)
+	_, _ = cache.GetOrFetch(2, time.Hour)
+	cache.Delete(2)
+	_, _ = cache.GetOrFetch(1, time.Hour)
+
+	assert.Eventually(t, func() bool {
+		return cache.Len() == 2
+	}, time.Second, time.Millisecond)
+	_, ok := cache.Get(2)
+
+	assert.True(t, ok)
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.PredictedPrefetches)
+	assert.Equal(t, uint64(1), stats.PredictedHits)
+}
+
+func TestCache_WithPredictivePrefetch_withoutDefaultTTL(t *testing.T) {
+	_, err := New[int, string](&testFetcher, getKey, WithPredictivePrefetch[int, string](10))
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestPredictor_observe_majority(t *testing.T) {
+	p := newPredictor[int](10)
+
+	for _, key := range []int{1, 2, 1, 3, 1, 2, 1} {
+		p.observe(key)
+	}
+	_, next, ok := p.observe(1)
+
+	assert.True(t, ok)
+	assert.Equal(t, 2, next)
+}
+
+func TestPredictor_observe_capacity(t *testing.T) {
+	p := newPredictor[int](1)
+
+	for _, key := range []int{1, 2, 3, 4, 3} {
+		p.observe(key)
+	}
+	_, _, ok := p.observe(4)
+
+	assert.False(t, ok)
+}
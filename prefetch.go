@@ -0,0 +1,94 @@
+package cachemem
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultPrefetchWorkers is the number of prefetches that may run at once,
+// unless set WithPrefetchWorkers.
+const DefaultPrefetchWorkers = 4
+
+// prefetcher tracks the prefetches running for a cache.
+type prefetcher[K comparable] struct {
+	mutex    sync.Mutex
+	inflight map[K]struct{}
+	workers  chan struct{}
+}
+
+func newPrefetcher[K comparable](workers int) *prefetcher[K] {
+	if workers == 0 {
+		workers = DefaultPrefetchWorkers
+	}
+	return &prefetcher[K]{
+		inflight: map[K]struct{}{},
+		workers:  make(chan struct{}, workers),
+	}
+}
+
+// claim marks the keys not already being prefetched as in flight, and
+// returns them.
+func (p *prefetcher[K]) claim(keys []K) []K {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var claimed []K
+	for _, key := range keys {
+		if _, ok := p.inflight[key]; !ok {
+			p.inflight[key] = struct{}{}
+			claimed = append(claimed, key)
+		}
+	}
+	return claimed
+}
+
+// release marks keys as no longer in flight.
+func (p *prefetcher[K]) release(keys []K) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(p.inflight, key)
+	}
+}
+
+// Prefetch fetches the records with the given keys that are missing from the
+// cache in the background, like FetchManyDefault, for records likely to be
+// needed soon. Unlike FetchManyDefault, the lookups are not recorded as
+// reads. Keys already being prefetched are skipped. Prefetches are low
+// priority: if as many prefetches as set WithPrefetchWorkers are running, the
+// prefetch is dropped and counted in Stats().DroppedPrefetches. Fetch errors
+// are discarded.
+func (cache *Cache[K, V]) Prefetch(keys ...K) {
+	cache.PrefetchTTL(cache.defaultTTL, keys...)
+}
+
+// PrefetchTTL is like Prefetch, but caches the prefetched records for
+// expiresIn rather than the duration set WithDefaultTTL.
+func (cache *Cache[K, V]) PrefetchTTL(expiresIn time.Duration, keys ...K) {
+	if cache.closed.Load() || cache.fetcher == nil {
+		return
+	}
+
+	claimed := cache.prefetcher.claim(keys)
+	if len(claimed) == 0 {
+		return
+	}
+
+	select {
+	case cache.prefetcher.workers <- struct{}{}:
+	default:
+		cache.prefetcher.release(claimed)
+		cache.stats.droppedPrefetches.Add(1)
+		return
+	}
+
+	go func() {
+		defer func() {
+			cache.prefetcher.release(claimed)
+			<-cache.prefetcher.workers
+		}()
+		_, found := cache.lookupMany(claimed)
+		_ = cache.fetchMissing(claimed, found, expiresIn)
+	}()
+}
@@ -0,0 +1,61 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Prefetch(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithDefaultTTL[int, string](time.Hour))
+
+	cache.Prefetch(1, 2)
+
+	assert.Eventually(t, func() bool {
+		return cache.Len() == 2
+	}, time.Second, time.Millisecond)
+	ttl, ok := cache.TTL(1)
+	assert.True(t, ok)
+	assert.LessOrEqual(t, ttl, time.Hour)
+}
+
+func TestCache_PrefetchTTL(t *testing.T) {
+	fetcher := TestFetcher{}
+	cache, _ := New[int, string](&fetcher, getKey, WithDefaultTTL[int, string](time.Hour))
+
+	cache.PrefetchTTL(time.Minute, 1)
+
+	assert.Eventually(t, func() bool {
+		return cache.Len() == 1
+	}, time.Second, time.Millisecond)
+	ttl, ok := cache.TTL(1)
+	assert.True(t, ok)
+	assert.LessOrEqual(t, ttl, time.Minute)
+}
+
+func TestCache_Prefetch_dropped(t *testing.T) {
+	fetcher := blockingFetcher{release: make(chan struct{})}
+	cache, _ := New[int, string](&fetcher, getKey, WithPrefetchWorkers[int, string](1))
+
+	cache.Prefetch(1)
+	cache.Prefetch(1)
+	cache.Prefetch(2)
+	close(fetcher.release)
+
+	assert.Equal(t, uint64(1), cache.Stats().DroppedPrefetches)
+	assert.Eventually(t, func() bool {
+		return cache.Len() == 1
+	}, time.Second, time.Millisecond)
+}
+
+type blockingFetcher struct {
+	TestFetcher
+	release chan struct{}
+}
+
+func (f *blockingFetcher) FetchMany(arrI []int) ([]string, error) {
+	<-f.release
+	return f.TestFetcher.FetchMany(arrI)
+}
@@ -0,0 +1,73 @@
+package cachemem
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// DeletePrefix deletes every record in cache whose key starts with prefix,
+// along with any records that depend on them, and returns the number of
+// records matched. If the cache was initialized WithOrderedIndex, the keys
+// are found in the index rather than by scanning the whole cache.
+func DeletePrefix[V any](cache *Cache[string, V], prefix string) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	keys := keysWithPrefixLocked(cache, prefix)
+	for _, key := range keys {
+		cache.deleteLocked(key, "")
+	}
+	return len(keys)
+}
+
+// DeleteGlob deletes every record in cache whose key matches pattern, using
+// the syntax of path.Match, along with any records that depend on them, and
+// returns the number of records matched. For example, "user:42:*" matches
+// every key starting with "user:42:" that contains no '/'. Keys are narrowed
+// down by the pattern's literal prefix, like DeletePrefix. DeleteGlob returns
+// path.ErrBadPattern if pattern is malformed.
+func DeleteGlob[V any](cache *Cache[string, V], pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	prefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		prefix = pattern[:i]
+	}
+	var keys []string
+	for _, key := range keysWithPrefixLocked(cache, prefix) {
+		if matched, _ := path.Match(pattern, key); matched {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		cache.deleteLocked(key, "")
+	}
+	return len(keys), nil
+}
+
+// keysWithPrefixLocked returns the keys in cache starting with prefix. The
+// caller must hold the mutex.
+func keysWithPrefixLocked[V any](cache *Cache[string, V], prefix string) []string {
+	if index, ok := cache.index.(*sortedKeys[string]); ok {
+		start, _ := slices.BinarySearch(index.keys, prefix)
+		end := start
+		for end < len(index.keys) && strings.HasPrefix(index.keys[end], prefix) {
+			end++
+		}
+		return slices.Clone(index.keys[start:end])
+	}
+
+	var keys []string
+	for key := range cache.store {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,52 @@
+package cachemem
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrefixCache(opts ...Option[string, string]) *Cache[string, string] {
+	cache, _ := NewStatic[string, string](func(s string) string { return s }, opts...)
+	cache.SetMany([]string{"user:4", "user:42:name", "user:42:email", "user:420", "team:42"}, time.Hour)
+	return cache
+}
+
+func TestDeletePrefix(t *testing.T) {
+	cache := newPrefixCache()
+
+	deleted := DeletePrefix(cache, "user:42")
+
+	assert.Equal(t, 3, deleted)
+	assert.ElementsMatch(t, []string{"user:4", "team:42"}, cache.Keys())
+}
+
+func TestDeletePrefix_orderedIndex(t *testing.T) {
+	cache := newPrefixCache(WithOrderedIndex[string, string]())
+
+	deleted := DeletePrefix(cache, "user:42")
+
+	assert.Equal(t, 3, deleted)
+	assert.Equal(t, []string{"team:42", "user:4"}, KeysSorted(cache))
+}
+
+func TestDeleteGlob(t *testing.T) {
+	cache := newPrefixCache()
+
+	deleted, err := DeleteGlob(cache, "user:42:*")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.ElementsMatch(t, []string{"user:4", "user:420", "team:42"}, cache.Keys())
+}
+
+func TestDeleteGlob_badPattern(t *testing.T) {
+	cache := newPrefixCache()
+
+	_, err := DeleteGlob(cache, "user:[")
+
+	assert.ErrorIs(t, err, path.ErrBadPattern)
+	assert.Equal(t, 5, cache.Len())
+}
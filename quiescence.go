@@ -0,0 +1,70 @@
+package cachemem
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// quiescence tracks when a cache was last used, so that its cleaner can
+// pause while the cache is not being used.
+type quiescence struct {
+	after      int64
+	lastActive atomic.Int64
+	paused     atomic.Bool
+	wake       chan struct{}
+}
+
+func newQuiescence(after time.Duration) *quiescence {
+	if after <= 0 {
+		return nil
+	}
+	return &quiescence{
+		after: int64(after),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// active records that the cache is being used, waking the cleaner if it is
+// paused.
+func (cache *Cache[K, V]) active() {
+	q := cache.quiescence
+	if q == nil {
+		return
+	}
+	q.lastActive.Store(cache.now())
+	if q.paused.Load() && q.paused.CompareAndSwap(true, false) {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// quiescent reports whether the cache has not been used for the duration set
+// WithQuiescence.
+func (cache *Cache[K, V]) quiescent() bool {
+	q := cache.quiescence
+	return q != nil && cache.now()-q.lastActive.Load() >= q.after
+}
+
+// awaitActivity pauses the cleaner until the cache is used again, returning
+// false if cleaning is stopped first.
+func (cache *Cache[K, V]) awaitActivity(ctx context.Context, stop <-chan struct{}) bool {
+	q := cache.quiescence
+	q.paused.Store(true)
+	// the cache may have been used before the cleaner was marked as paused,
+	// without waking it
+	if !cache.quiescent() && q.paused.CompareAndSwap(true, false) {
+		return true
+	}
+
+	select {
+	case <-q.wake:
+		return true
+	case <-stop:
+	case <-ctx.Done():
+	}
+	q.paused.Store(false)
+	return false
+}
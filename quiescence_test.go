@@ -0,0 +1,49 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithQuiescence(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithCleanFrequency[int, string](time.Millisecond),
+		WithJanitor[int, string](),
+		WithQuiescence[int, string](5*time.Millisecond),
+	)
+	defer cache.Close()
+
+	assert.Eventually(t, func() bool {
+		return cache.CleaningStatus().Paused
+	}, time.Second, time.Millisecond)
+	cache.Set("1", time.Millisecond)
+
+	assert.False(t, cache.CleaningStatus().Paused)
+	assert.Eventually(t, func() bool {
+		return cache.Stats().Expired == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestCache_WithQuiescence_stop(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithCleanFrequency[int, string](time.Millisecond),
+		WithJanitor[int, string](),
+		WithQuiescence[int, string](time.Millisecond),
+	)
+	assert.Eventually(t, func() bool {
+		return cache.CleaningStatus().Paused
+	}, time.Second, time.Millisecond)
+
+	cache.Close()
+
+	assert.Eventually(t, func() bool {
+		status := cache.CleaningStatus()
+		return !status.Running && !status.Paused
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,35 @@
+package cachemem
+
+import "sync"
+
+// shadow simulates an LRU cache of a different capacity over the same stream
+// of reads, without serving any of them.
+type shadow[K comparable] struct {
+	mutex    sync.Mutex
+	capacity int
+	lru      *lru[K]
+}
+
+func newShadow[K comparable](capacity int) *shadow[K] {
+	if capacity <= 0 {
+		return nil
+	}
+	return &shadow[K]{
+		capacity: capacity,
+		lru:      newLRU[K](),
+	}
+}
+
+// access records a read of key, and reports whether the simulated cache
+// would have served it.
+func (s *shadow[K]) access(key K) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hit := s.lru.touch(key)
+	if s.lru.len() > s.capacity {
+		oldest, _ := s.lru.oldest()
+		s.lru.remove(oldest)
+	}
+	return hit
+}
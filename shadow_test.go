@@ -0,0 +1,37 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithShadow(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithShadow[int, string](1))
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+
+	cache.Get(1)
+	cache.Get(1)
+	cache.Get(2)
+	cache.Get(1)
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(4), stats.Hits)
+	assert.Equal(t, uint64(1), stats.ShadowHits)
+	assert.Equal(t, uint64(3), stats.ShadowMisses)
+	assert.Equal(t, 0.25, stats.ShadowHitRatio())
+}
+
+func TestCache_WithShadow_disabled(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	cache.Get(1)
+	cache.Get(2)
+
+	stats := cache.Stats()
+	assert.Equal(t, 0.5, stats.HitRatio())
+	assert.Equal(t, uint64(0), stats.ShadowHits+stats.ShadowMisses)
+}
@@ -0,0 +1,40 @@
+package cachemem
+
+import (
+	"math"
+	"time"
+)
+
+// slide extends the expiry of the sliding record with key key, if it has
+// not expired, to its expiry duration from now, but no later than its
+// maximum lifetime allows.
+func (cache *Cache[K, V]) slide(key K) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	e, ok := cache.store[key]
+	now := cache.now()
+	if !ok || e.idle == 0 || e.hasExpired(now) || e.idle > math.MaxInt64-now {
+		return
+	}
+	e.expiresAt = min(now+e.idle, e.limit)
+	cache.store[key] = e
+}
+
+// SetWithIdleTTL writes a new entry to the cache like Set, expiring it once
+// it has not been read for idleTTL, and after absoluteTTL regardless, in
+// place of the cache's idle timeout. An idleTTL of zero or NoExpiry disables
+// the idle timeout for the entry.
+func (cache *Cache[K, V]) SetWithIdleTTL(value V, idleTTL, absoluteTTL time.Duration) {
+	key, err := cache.keyOf(value)
+	if err != nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if ttl, ok := cache.ttlOverrides[key]; ok {
+		absoluteTTL = ttl
+	}
+	cache.storeLocked(key, value, idleTTL, absoluteTTL, nil, "")
+}
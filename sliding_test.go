@@ -0,0 +1,102 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithSlidingExpiration(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithClockResolution[int, string](time.Hour),
+		WithSlidingExpiration[int, string](NoExpiry),
+	)
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+
+	advance(cache, 40*time.Second)
+	_, okRead := cache.Get(1)
+	advance(cache, 40*time.Second)
+	_, okSlid := cache.Get(1)
+	advance(cache, 61*time.Second)
+	_, okIdle := cache.Get(1)
+
+	assert.True(t, okRead)
+	assert.True(t, okSlid)
+	assert.False(t, okIdle)
+}
+
+func TestCache_WithSlidingExpiration_maxLifetime(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithClockResolution[int, string](time.Hour),
+		WithSlidingExpiration[int, string](90*time.Second),
+	)
+	defer cache.Close()
+	cache.Set("1", time.Minute)
+
+	advance(cache, 40*time.Second)
+	_, okRead := cache.Get(1)
+	advance(cache, 40*time.Second)
+	values := cache.GetMany([]int{1})
+	advance(cache, 11*time.Second)
+	_, okLimit := cache.Get(1)
+
+	assert.True(t, okRead)
+	assert.Equal(t, []string{"1"}, values)
+	assert.False(t, okLimit)
+}
+
+func TestCache_WithSlidingExpiration_invalid(t *testing.T) {
+	_, err := New[int, string](&testFetcher, getKey, WithSlidingExpiration[int, string](0))
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestCache_WithIdleTTL(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithClockResolution[int, string](time.Hour),
+		WithIdleTTL[int, string](time.Minute),
+	)
+	defer cache.Close()
+	cache.Set("1", 90*time.Second)
+	cache.Set("2", time.Hour)
+
+	advance(cache, 40*time.Second)
+	_, okRead := cache.Get(1)
+	advance(cache, 40*time.Second)
+	_, okSlid := cache.Get(1)
+	_, okIdle := cache.Get(2)
+	advance(cache, 11*time.Second)
+	_, okAbsolute := cache.Get(1)
+
+	assert.True(t, okRead)
+	assert.True(t, okSlid)
+	assert.False(t, okIdle)
+	assert.False(t, okAbsolute)
+}
+
+func TestCache_SetWithIdleTTL(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithClockResolution[int, string](time.Hour),
+		WithIdleTTL[int, string](time.Minute),
+	)
+	defer cache.Close()
+	cache.SetWithIdleTTL("1", 0, time.Hour)
+	cache.SetWithIdleTTL("2", time.Second, time.Hour)
+
+	advance(cache, 2*time.Minute)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+
+	assert.True(t, ok1)
+	assert.False(t, ok2)
+}
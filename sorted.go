@@ -0,0 +1,101 @@
+package cachemem
+
+import (
+	"cmp"
+	"slices"
+)
+
+// sortedKeys is a key index that keeps keys in ascending order.
+type sortedKeys[K cmp.Ordered] struct {
+	keys []K
+}
+
+func (s *sortedKeys[K]) add(key K) {
+	i, found := slices.BinarySearch(s.keys, key)
+	if !found {
+		s.keys = slices.Insert(s.keys, i, key)
+	}
+}
+
+func (s *sortedKeys[K]) remove(key K) {
+	i, found := slices.BinarySearch(s.keys, key)
+	if found {
+		s.keys = slices.Delete(s.keys, i, i+1)
+	}
+}
+
+func (s *sortedKeys[K]) reset() {
+	s.keys = nil
+}
+
+// WithOrderedIndex maintains an index of keys in ascending order, so that
+// KeysSorted and RangeSorted do not need to sort the cache's keys on every
+// call, at the cost of slower writes of new keys.
+func WithOrderedIndex[K cmp.Ordered, V any]() Option[K, V] {
+	return withIndex[K, V](&sortedKeys[K]{})
+}
+
+// sortedKeysLocked returns the keys of all records in ascending order.
+// The caller must hold the mutex.
+func sortedKeysLocked[K cmp.Ordered, V any](cache *Cache[K, V]) []K {
+	if index, ok := cache.index.(*sortedKeys[K]); ok {
+		return index.keys
+	}
+
+	keys := make([]K, 0, len(cache.store))
+	for key := range cache.store {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// KeysSorted returns the keys of the records in cache that exist and have not
+// expired, in ascending order.
+func KeysSorted[K cmp.Ordered, V any](cache *Cache[K, V]) []K {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	now := cache.now()
+	var keys []K
+	for _, key := range sortedKeysLocked(cache) {
+		e := cache.store[key]
+		if !e.hasExpired(now) && e.err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// RangeSorted calls fn for each record in cache that exists and has not
+// expired with a key from from (inclusive) to to (exclusive), in ascending
+// key order, until fn returns false. fn is called without holding the
+// cache's lock, so it may use the cache.
+func RangeSorted[K cmp.Ordered, V any](cache *Cache[K, V], from, to K, fn func(K, V) bool) {
+	type record struct {
+		key   K
+		value V
+	}
+
+	cache.mutex.RLock()
+	keys := sortedKeysLocked(cache)
+	start, _ := slices.BinarySearch(keys, from)
+	now := cache.now()
+	var records []record
+	for _, key := range keys[start:] {
+		if key >= to {
+			break
+		}
+		e := cache.store[key]
+		if !e.hasExpired(now) && e.err == nil {
+			records = append(records, record{key: key, value: e.value})
+		}
+	}
+	cache.mutex.RUnlock()
+
+	for _, r := range records {
+		if !fn(r.key, r.value) {
+			return
+		}
+	}
+}
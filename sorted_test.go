@@ -0,0 +1,64 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysSorted(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("3", time.Hour)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Nanosecond)
+	cache.Set("4", time.Hour)
+
+	time.Sleep(10 * time.Nanosecond)
+	actual := KeysSorted(cache)
+
+	assert.Equal(t, []int{1, 3, 4}, actual)
+}
+
+func TestKeysSorted_orderedIndex(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithOrderedIndex[int, string]())
+	cache.Set("3", time.Hour)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	cache.Set("4", time.Hour)
+	cache.Delete(2)
+
+	actual := KeysSorted(cache)
+
+	assert.Equal(t, []int{1, 3, 4}, actual)
+}
+
+func TestRangeSorted(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithOrderedIndex[int, string]())
+	for _, value := range []string{"5", "1", "4", "2", "3"} {
+		cache.Set(value, time.Hour)
+	}
+
+	var actual []string
+	RangeSorted(cache, 2, 5, func(key int, value string) bool {
+		actual = append(actual, value)
+		return true
+	})
+
+	assert.Equal(t, []string{"2", "3", "4"}, actual)
+}
+
+func TestRangeSorted_stop(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	for _, value := range []string{"3", "1", "2"} {
+		cache.Set(value, time.Hour)
+	}
+
+	var actual []int
+	RangeSorted(cache, 0, 10, func(key int, value string) bool {
+		actual = append(actual, key)
+		return len(actual) < 2
+	})
+
+	assert.Equal(t, []int{1, 2}, actual)
+}
@@ -0,0 +1,216 @@
+package cachemem
+
+import "sync/atomic"
+
+// Stats holds counters describing the activity of a cache since it was
+// initialized. Apart from Cost, CostHistogram and Permanent, counters only
+// ever increase, so activity over an interval is the difference between two
+// snapshots.
+type Stats struct {
+	// Cost is the total cost of the records in the cache, see WithWeigher.
+	Cost int64
+	// CostHistogram counts the records in the cache by cost, in buckets of
+	// powers of two: CostHistogram[i] is the number of records costing at
+	// least 2^(i-1) and less than 2^i, and CostHistogram[0] the number of
+	// records costing nothing. It ends at the last non-empty bucket. See
+	// LargestEntries for the most costly records.
+	CostHistogram []int
+	// Permanent is the number of records in the cache that never expire, see
+	// NoExpiry.
+	Permanent int
+	// Hits is the number of reads served from the cache.
+	Hits uint64
+	// NegativeHits is the number of reads served a cached fetch error.
+	NegativeHits uint64
+	// Misses is the number of reads not served from the cache.
+	Misses uint64
+	// ShadowHits is the number of reads the shadow cache would have served.
+	ShadowHits uint64
+	// ShadowMisses is the number of reads the shadow cache would have missed.
+	ShadowMisses uint64
+	// Bypasses is the number of GetOrFetch calls sent straight to the fetcher.
+	Bypasses uint64
+	// BypassHits is the number of bypasses the cache could have served.
+	BypassHits uint64
+	// BypassMismatches is the number of bypasses for which the cache would have
+	// served a record that differed from the fetched one.
+	BypassMismatches uint64
+	// Validations is the number of cache hits compared with the fetched record.
+	Validations uint64
+	// ValidationMismatches is the number of validated cache hits that differed
+	// from the fetched record.
+	ValidationMismatches uint64
+	// Audited is the number of records re-fetched by Audit.
+	Audited uint64
+	// AuditedStale is the number of records re-fetched by Audit that were
+	// stale.
+	AuditedStale uint64
+	// Added is the number of records written under a key not in the cache.
+	Added uint64
+	// Expired is the number of expired records removed by the cleaner.
+	Expired uint64
+	// Evicted is the number of records evicted to keep the cache within its
+	// maximum size.
+	Evicted uint64
+	// UniqueKeys is an estimate of the number of distinct keys written to the
+	// cache, accurate to within a few percent, if the cache was initialized
+	// WithUniqueKeys.
+	UniqueKeys uint64
+	// RejectedAdmissions is the number of fetched records discarded by the
+	// cache's admission policy.
+	RejectedAdmissions uint64
+	// Compactions is the number of times the cache rebuilt its internal map to
+	// release memory held for records since removed.
+	Compactions uint64
+	// RejectedWrites is the number of writes dropped while the cache was frozen.
+	RejectedWrites uint64
+	// KeyMismatches is the number of records fetched by GetOrFetch whose key
+	// differed from the requested key, see WithKeyMismatch.
+	KeyMismatches uint64
+	// DuplicateFetches is the number of records returned by the fetcher for
+	// FetchMany that were superseded by a later record with the same key.
+	DuplicateFetches uint64
+	// UnrequestedFetches is the number of records returned by the fetcher for
+	// FetchMany with keys that were not requested, see WithStrictFetchMany.
+	UnrequestedFetches uint64
+	// DroppedPrefetches is the number of calls to Prefetch dropped because too
+	// many prefetches were running.
+	DroppedPrefetches uint64
+	// PredictedPrefetches is the number of prefetches of keys predicted to be
+	// read next, see WithPredictivePrefetch.
+	PredictedPrefetches uint64
+	// PredictedHits is the number of cache hits on keys predicted to be read
+	// next.
+	PredictedHits uint64
+	// DroppedExpirations is the number of expired records not sent to the
+	// channel returned by Expired because it was full.
+	DroppedExpirations uint64
+}
+
+// HitRatio returns the fraction of reads served from the cache.
+func (s Stats) HitRatio() float64 {
+	return ratio(s.Hits, s.Misses)
+}
+
+// StalenessDivergence returns the fraction of audited records that were stale.
+func (s Stats) StalenessDivergence() float64 {
+	return ratio(s.AuditedStale, s.Audited-s.AuditedStale)
+}
+
+// ShadowHitRatio returns the fraction of reads the shadow cache would have
+// served.
+func (s Stats) ShadowHitRatio() float64 {
+	return ratio(s.ShadowHits, s.ShadowMisses)
+}
+
+func ratio(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+type stats struct {
+	hits                 atomic.Uint64
+	negativeHits         atomic.Uint64
+	misses               atomic.Uint64
+	shadowHits           atomic.Uint64
+	shadowMisses         atomic.Uint64
+	bypasses             atomic.Uint64
+	bypassHits           atomic.Uint64
+	bypassMismatches     atomic.Uint64
+	validations          atomic.Uint64
+	validationMismatches atomic.Uint64
+	audited              atomic.Uint64
+	auditedStale         atomic.Uint64
+	added                atomic.Uint64
+	expired              atomic.Uint64
+	evicted              atomic.Uint64
+	rejectedAdmissions   atomic.Uint64
+	compactions          atomic.Uint64
+	rejectedWrites       atomic.Uint64
+	keyMismatches        atomic.Uint64
+	duplicateFetches     atomic.Uint64
+	unrequestedFetches   atomic.Uint64
+	droppedPrefetches    atomic.Uint64
+	predictedPrefetches  atomic.Uint64
+	predictedHits        atomic.Uint64
+	droppedExpirations   atomic.Uint64
+}
+
+// Stats returns a snapshot of the cache's counters.
+func (cache *Cache[K, V]) Stats() Stats {
+	cache.mutex.RLock()
+	uniqueKeys := cache.uniqueKeys.estimate()
+	cost := cache.cost
+	costs := cache.costs.buckets()
+	permanent := cache.permanent
+	cache.mutex.RUnlock()
+
+	return Stats{
+		Cost:                 cost,
+		CostHistogram:        costs,
+		Permanent:            permanent,
+		Hits:                 cache.stats.hits.Load(),
+		NegativeHits:         cache.stats.negativeHits.Load(),
+		Misses:               cache.stats.misses.Load(),
+		ShadowHits:           cache.stats.shadowHits.Load(),
+		ShadowMisses:         cache.stats.shadowMisses.Load(),
+		Bypasses:             cache.stats.bypasses.Load(),
+		BypassHits:           cache.stats.bypassHits.Load(),
+		BypassMismatches:     cache.stats.bypassMismatches.Load(),
+		Validations:          cache.stats.validations.Load(),
+		ValidationMismatches: cache.stats.validationMismatches.Load(),
+		Audited:              cache.stats.audited.Load(),
+		AuditedStale:         cache.stats.auditedStale.Load(),
+		Added:                cache.stats.added.Load(),
+		Expired:              cache.stats.expired.Load(),
+		Evicted:              cache.stats.evicted.Load(),
+		UniqueKeys:           uniqueKeys,
+		RejectedAdmissions:   cache.stats.rejectedAdmissions.Load(),
+		Compactions:          cache.stats.compactions.Load(),
+		RejectedWrites:       cache.stats.rejectedWrites.Load(),
+		KeyMismatches:        cache.stats.keyMismatches.Load(),
+		DuplicateFetches:     cache.stats.duplicateFetches.Load(),
+		UnrequestedFetches:   cache.stats.unrequestedFetches.Load(),
+		DroppedPrefetches:    cache.stats.droppedPrefetches.Load(),
+		PredictedPrefetches:  cache.stats.predictedPrefetches.Load(),
+		PredictedHits:        cache.stats.predictedHits.Load(),
+		DroppedExpirations:   cache.stats.droppedExpirations.Load(),
+	}
+}
+
+type read int
+
+const (
+	readHit read = iota
+	readNegativeHit
+	readMiss
+)
+
+func (cache *Cache[K, V]) recordRead(key K, r read) {
+	switch r {
+	case readHit:
+		cache.stats.hits.Add(1)
+	case readNegativeHit:
+		cache.stats.negativeHits.Add(1)
+	case readMiss:
+		cache.stats.misses.Add(1)
+	}
+
+	if cache.admission != nil {
+		cache.admission.Record(key)
+	}
+	if cache.predictor != nil {
+		cache.predict(key, r)
+	}
+
+	if cache.shadow == nil {
+		return
+	}
+	if cache.shadow.access(key) {
+		cache.stats.shadowHits.Add(1)
+	} else {
+		cache.stats.shadowMisses.Add(1)
+	}
+}
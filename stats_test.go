@@ -0,0 +1,46 @@
+package cachemem
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Stats(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithUniqueKeys[int, string]())
+	cache.Set("1", time.Hour)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Nanosecond)
+
+	time.Sleep(10 * time.Nanosecond)
+	cache.clean()
+	cache.Get(1)
+	cache.Get(2)
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Added)
+	assert.Equal(t, uint64(1), stats.Expired)
+	assert.Equal(t, uint64(2), stats.UniqueKeys)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestCache_Stats_uniqueKeys(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey, WithUniqueKeys[int, string]())
+	for i := 0; i < 100000; i++ {
+		cache.Set(strconv.Itoa(i%50000), time.Hour)
+	}
+
+	actual := cache.Stats().UniqueKeys
+	assert.InEpsilon(t, 50000, actual, 0.05)
+}
+
+func TestCache_Stats_uniqueKeysDisabled(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	assert.Nil(t, cache.uniqueKeys)
+	assert.Equal(t, uint64(0), cache.Stats().UniqueKeys)
+}
@@ -0,0 +1,134 @@
+package cachemem
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"sync"
+)
+
+// Admission decides whether a record fetched into a full cache should be
+// admitted, evicting the least recently used record, or discarded.
+type Admission[K comparable] interface {
+	// Record records a read of key.
+	Record(key K)
+	// Admit reports whether candidate should replace victim in the cache.
+	Admit(candidate, victim K) bool
+}
+
+const (
+	sketchDepth      = 4
+	maxSketchCounter = 15
+)
+
+// TinyLFU is an Admission policy that admits a candidate only if it has been
+// read more frequently than the victim it would replace, so that keys read
+// only once don't displace frequently read ones. Frequencies are estimated
+// with a count-min sketch behind a doorkeeper Bloom filter, and periodically
+// halved so that the policy adapts to changes in popularity.
+type TinyLFU[K comparable] struct {
+	mutex      sync.Mutex
+	seed       maphash.Seed
+	sketch     [sketchDepth][]uint8
+	doorkeeper []uint64
+	additions  int
+	sampleSize int
+}
+
+// NewTinyLFU initializes a TinyLFU admission policy for a cache holding
+// around capacity records.
+func NewTinyLFU[K comparable](capacity int) *TinyLFU[K] {
+	width := 1 << bits.Len(uint(max(capacity, 16)-1))
+	t := &TinyLFU[K]{
+		seed:       maphash.MakeSeed(),
+		doorkeeper: make([]uint64, width/8),
+		sampleSize: 10 * width,
+	}
+	for i := range t.sketch {
+		t.sketch[i] = make([]uint8, width)
+	}
+	return t
+}
+
+// indexes returns the sketch column of hash for each row.
+func (t *TinyLFU[K]) indexes(hash uint64) [sketchDepth]int {
+	var indexes [sketchDepth]int
+	mask := uint32(len(t.sketch[0]) - 1)
+	h1, h2 := uint32(hash), uint32(hash>>32)
+	for i := range indexes {
+		indexes[i] = int((h1 + uint32(i)*h2) & mask)
+	}
+	return indexes
+}
+
+// doorkeeperBits returns the doorkeeper bits set for hash.
+func (t *TinyLFU[K]) doorkeeperBits(hash uint64) [2]uint64 {
+	n := uint64(len(t.doorkeeper) * 64)
+	return [2]uint64{hash % n, (hash >> 32) % n}
+}
+
+func (t *TinyLFU[K]) inDoorkeeper(hash uint64) bool {
+	for _, bit := range t.doorkeeperBits(hash) {
+		if t.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Record records a read of key.
+func (t *TinyLFU[K]) Record(key K) {
+	hash := hashKey(t.seed, key)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.inDoorkeeper(hash) {
+		for _, bit := range t.doorkeeperBits(hash) {
+			t.doorkeeper[bit/64] |= 1 << (bit % 64)
+		}
+	} else {
+		for row, column := range t.indexes(hash) {
+			if t.sketch[row][column] < maxSketchCounter {
+				t.sketch[row][column]++
+			}
+		}
+	}
+
+	t.additions++
+	if t.additions >= t.sampleSize {
+		t.reset()
+	}
+}
+
+// reset halves every frequency and clears the doorkeeper.
+func (t *TinyLFU[K]) reset() {
+	for row := range t.sketch {
+		for column := range t.sketch[row] {
+			t.sketch[row][column] /= 2
+		}
+	}
+	clear(t.doorkeeper)
+	t.additions = 0
+}
+
+// estimate returns the estimated read frequency of the key with hash hash.
+func (t *TinyLFU[K]) estimate(hash uint64) int {
+	frequency := maxSketchCounter
+	for row, column := range t.indexes(hash) {
+		frequency = min(frequency, int(t.sketch[row][column]))
+	}
+	if t.inDoorkeeper(hash) {
+		frequency++
+	}
+	return frequency
+}
+
+// Admit reports whether candidate has been read more frequently than victim.
+func (t *TinyLFU[K]) Admit(candidate, victim K) bool {
+	candidateHash, victimHash := hashKey(t.seed, candidate), hashKey(t.seed, victim)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.estimate(candidateHash) > t.estimate(victimHash)
+}
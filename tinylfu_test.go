@@ -0,0 +1,57 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTinyLFU_Admit(t *testing.T) {
+	tinyLFU := NewTinyLFU[int](100)
+	for i := 0; i < 5; i++ {
+		tinyLFU.Record(1)
+	}
+	tinyLFU.Record(2)
+
+	assert.True(t, tinyLFU.Admit(1, 2))
+	assert.False(t, tinyLFU.Admit(2, 1))
+	assert.False(t, tinyLFU.Admit(3, 2))
+}
+
+func TestTinyLFU_reset(t *testing.T) {
+	tinyLFU := NewTinyLFU[int](100)
+	for i := 0; i < 5; i++ {
+		tinyLFU.Record(1)
+	}
+
+	hash := hashKey(tinyLFU.seed, 1)
+	assert.Equal(t, 5, tinyLFU.estimate(hash))
+	tinyLFU.reset()
+	assert.Equal(t, 2, tinyLFU.estimate(hash))
+}
+
+func TestCache_WithAdmission(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithMaxEntries[int, string](2),
+		WithAdmission[int, string](NewTinyLFU[int](2)),
+	)
+	_, _ = cache.GetOrFetch(1, time.Hour)
+	_, _ = cache.GetOrFetch(2, time.Hour)
+	for i := 0; i < 5; i++ {
+		cache.Get(1)
+		cache.Get(2)
+	}
+
+	_, _ = cache.GetOrFetch(3, time.Hour)
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+	_, ok3 := cache.Get(3)
+
+	assert.True(t, ok1)
+	assert.True(t, ok2)
+	assert.False(t, ok3)
+	assert.Equal(t, uint64(1), cache.Stats().RejectedAdmissions)
+}
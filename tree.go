@@ -0,0 +1,157 @@
+package cachemem
+
+import "strings"
+
+// TreeCache is a Cache keyed by hierarchical paths such as "org/team/user".
+// A trie over the keys allows whole subtrees to be read or invalidated
+// without scanning the cache.
+type TreeCache[V any] struct {
+	Cache[string, V]
+	tree *pathTrie
+}
+
+// NewTree initializes a new, empty TreeCache whose keys are paths delimited
+// by separator, configured by opts like New.
+func NewTree[V any](fetcher Fetcher[string, V], getKey func(V) string, separator string, opts ...Option[string, V]) (*TreeCache[V], error) {
+	tree := newPathTrie(separator)
+	cfg := newConfig(append(opts, withIndex[string, V](tree)))
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	cache := &TreeCache[V]{tree: tree}
+	cache.init(fetcher, getKey, cfg)
+	if cfg.ClockResolution > 0 {
+		cache.startClock(cfg.ClockResolution)
+	}
+	if cfg.Janitor {
+		cache.startJanitor()
+	}
+	return cache, nil
+}
+
+// GetSubtree retrieves every record that exists and has not expired whose key
+// is prefix or a descendant of prefix, keyed by their keys.
+func (cache *TreeCache[V]) GetSubtree(prefix string) map[string]V {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	now := cache.now()
+	values := map[string]V{}
+	for _, key := range cache.tree.subtree(prefix) {
+		e, ok := cache.store[key]
+		if ok && !e.hasExpired(now) && e.err == nil {
+			values[key] = e.value
+		}
+	}
+
+	return values
+}
+
+// InvalidateSubtree deletes every record whose key is prefix or a descendant
+// of prefix, and returns the number of records deleted.
+func (cache *TreeCache[V]) InvalidateSubtree(prefix string) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	keys := cache.tree.subtree(prefix)
+	for _, key := range keys {
+		cache.deleteLocked(key, ReasonInvalidated)
+	}
+
+	return len(keys)
+}
+
+type pathNode struct {
+	children map[string]*pathNode
+	key      string
+	isKey    bool
+}
+
+// pathTrie indexes keys by their path segments.
+type pathTrie struct {
+	separator string
+	root      *pathNode
+}
+
+func newPathTrie(separator string) *pathTrie {
+	return &pathTrie{
+		separator: separator,
+		root:      &pathNode{children: map[string]*pathNode{}},
+	}
+}
+
+func (t *pathTrie) segments(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, t.separator)
+}
+
+func (t *pathTrie) add(key string) {
+	node := t.root
+	for _, segment := range t.segments(key) {
+		child, ok := node.children[segment]
+		if !ok {
+			child = &pathNode{children: map[string]*pathNode{}}
+			node.children[segment] = child
+		}
+		node = child
+	}
+	node.key = key
+	node.isKey = true
+}
+
+func (t *pathTrie) remove(key string) {
+	segments := t.segments(key)
+	path := []*pathNode{t.root}
+	node := t.root
+	for _, segment := range segments {
+		child, ok := node.children[segment]
+		if !ok {
+			return
+		}
+		node = child
+		path = append(path, node)
+	}
+	node.isKey = false
+
+	// prune nodes that no longer lead to any key
+	for i := len(segments); i > 0; i-- {
+		if path[i].isKey || len(path[i].children) > 0 {
+			return
+		}
+		delete(path[i-1].children, segments[i-1])
+	}
+}
+
+func (t *pathTrie) reset() {
+	t.root = &pathNode{children: map[string]*pathNode{}}
+}
+
+// subtree returns the keys at or below prefix.
+func (t *pathTrie) subtree(prefix string) []string {
+	node := t.root
+	for _, segment := range t.segments(prefix) {
+		child, ok := node.children[segment]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	var keys []string
+	stack := []*pathNode{node}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node.isKey {
+			keys = append(keys, node.key)
+		}
+		for _, child := range node.children {
+			stack = append(stack, child)
+		}
+	}
+
+	return keys
+}
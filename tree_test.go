@@ -0,0 +1,63 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pathKey(s string) string {
+	return s
+}
+
+func TestTreeCache_GetSubtree(t *testing.T) {
+	cache, _ := NewTree[string](nil, pathKey, "/")
+	cache.Set("org/team", time.Hour)
+	cache.Set("org/team/alice", time.Hour)
+	cache.Set("org/team/bob", time.Nanosecond)
+	cache.Set("org/teammate", time.Hour)
+	cache.Set("other/team", time.Hour)
+
+	time.Sleep(10 * time.Nanosecond)
+	actual := cache.GetSubtree("org/team")
+
+	assert.Equal(t, map[string]string{
+		"org/team":       "org/team",
+		"org/team/alice": "org/team/alice",
+	}, actual)
+}
+
+func TestTreeCache_GetSubtree_root(t *testing.T) {
+	cache, _ := NewTree[string](nil, pathKey, "/")
+	cache.Set("a", time.Hour)
+	cache.Set("b/c", time.Hour)
+
+	actual := cache.GetSubtree("")
+	assert.Len(t, actual, 2)
+}
+
+func TestTreeCache_InvalidateSubtree(t *testing.T) {
+	cache, _ := NewTree[string](nil, pathKey, "/")
+	cache.Set("org/team/alice", time.Hour)
+	cache.Set("org/team/bob", time.Hour)
+	cache.Set("org/other", time.Hour)
+
+	n := cache.InvalidateSubtree("org/team")
+	_, okAlice := cache.Get("org/team/alice")
+	_, okOther := cache.Get("org/other")
+
+	assert.Equal(t, 2, n)
+	assert.False(t, okAlice)
+	assert.True(t, okOther)
+	assert.Empty(t, cache.GetSubtree("org/team"))
+}
+
+func TestTreeCache_Delete(t *testing.T) {
+	cache, _ := NewTree[string](nil, pathKey, "/")
+	cache.Set("org/team/alice", time.Hour)
+
+	cache.Delete("org/team/alice")
+
+	assert.Empty(t, cache.tree.root.children)
+}
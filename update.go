@@ -0,0 +1,31 @@
+package cachemem
+
+// Update replaces the record with key key, if it exists and has not expired,
+// with the result of calling fn on it. The record keeps its key, its
+// dependencies and, unless the cache was initialized WithExpiryFunc, its
+// expiry. fn is called while holding the cache's lock, so it must not use
+// the cache. Update reports whether the record was updated, which it is not
+// while the cache is frozen.
+func (cache *Cache[K, V]) Update(key K, fn func(V) V) bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	e, ok := cache.store[key]
+	if !ok || e.hasExpired(cache.now()) || e.err != nil {
+		return false
+	}
+	if cache.frozen.Load() {
+		cache.stats.rejectedWrites.Add(1)
+		return false
+	}
+
+	e.value = fn(e.value)
+	if cache.expiryFunc != nil {
+		if expiresAt, ok := cache.expiryFunc(e.value); ok {
+			e.expiresAt = cache.deadline(expiresAt)
+		}
+	}
+
+	cache.setLocked(key, e, cache.dependencies[key], "")
+	return true
+}
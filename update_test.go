@@ -0,0 +1,83 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type session struct {
+	id        int
+	expiresAt time.Time
+}
+
+func sessionKey(s session) int {
+	return s.id
+}
+
+func TestCache_Update(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+
+	ok := cache.Update(1, func(s string) string { return s + "0" })
+	actual, _ := cache.Get(1)
+
+	assert.True(t, ok)
+	assert.Equal(t, "10", actual)
+}
+
+func TestCache_Update_frozen(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.Freeze()
+
+	called := false
+	ok := cache.Update(1, func(s string) string {
+		called = true
+		return s + "0"
+	})
+	actual, _ := cache.Get(1)
+
+	assert.False(t, ok)
+	assert.False(t, called)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, uint64(1), cache.Stats().RejectedWrites)
+}
+
+func TestCache_Update_missing(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	ok := cache.Update(1, func(s string) string { return s + "0" })
+
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_Update_expiryFunc(t *testing.T) {
+	cache, _ := New[int, session](nil, sessionKey, WithExpiryFunc[int, session](func(s session) (time.Time, bool) {
+		return s.expiresAt, !s.expiresAt.IsZero()
+	}))
+	cache.Set(session{id: 1}, time.Hour)
+
+	cache.Update(1, func(s session) session {
+		s.expiresAt = time.Now()
+		return s
+	})
+	time.Sleep(10 * time.Nanosecond)
+	_, ok := cache.Get(1)
+
+	assert.False(t, ok)
+}
+
+func TestCache_Update_keepsDependencies(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.SetWithDeps("2", time.Hour, 1)
+
+	cache.Update(2, func(s string) string { return s })
+	cache.Delete(1)
+	_, ok := cache.Get(2)
+
+	assert.False(t, ok)
+}
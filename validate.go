@@ -0,0 +1,23 @@
+package cachemem
+
+import "math/rand"
+
+// shouldValidate reports whether a cache hit should be compared with the
+// fetched record.
+func (cache *Cache[K, V]) shouldValidate() bool {
+	return cache.validationFraction > 0 && rand.Float64() < cache.validationFraction
+}
+
+// validate fetches the record with key key and compares it with cached,
+// recording a mismatch if they differ. The cached record is left as is.
+func (cache *Cache[K, V]) validate(key K, cached V) {
+	fetchedValue, _, err := cache.fetchOne(key)
+	if err != nil {
+		return
+	}
+
+	cache.stats.validations.Add(1)
+	if !cache.equal(cached, fetchedValue) {
+		cache.stats.validationMismatches.Add(1)
+	}
+}
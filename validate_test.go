@@ -0,0 +1,28 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_WithValidationFraction(t *testing.T) {
+	cache, _ := New[int, string](
+		&testFetcher,
+		getKey,
+		WithValidationFraction[int, string](1),
+		WithEqual[int, string](equalStrings),
+	)
+	cache.Set("1", time.Hour)
+	cache.Set("02", time.Hour)
+
+	actual1, _ := cache.GetOrFetch(1, time.Hour)
+	actual2, _ := cache.GetOrFetch(2, time.Hour)
+
+	assert.Equal(t, "1", actual1)
+	assert.Equal(t, "02", actual2)
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Validations)
+	assert.Equal(t, uint64(1), stats.ValidationMismatches)
+}
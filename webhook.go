@@ -0,0 +1,123 @@
+package cachemem
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SignatureHeader is the request header carrying the signature of an
+// invalidation request: "sha256=" followed by the hex-encoded HMAC-SHA256 of
+// the request body.
+const SignatureHeader = "X-Cachemem-Signature"
+
+const maxInvalidationBodySize = 1 << 20
+
+// Invalidation is the JSON body of an invalidation request.
+type Invalidation[K comparable] struct {
+	// Keys are the keys of records to delete.
+	Keys []K `json:"keys"`
+	// Prefixes are key prefixes of records to delete. They are only supported
+	// by caches with string keys.
+	Prefixes []string `json:"prefixes"`
+}
+
+type invalidationResponse struct {
+	Invalidated int `json:"invalidated"`
+}
+
+// InvalidationHandler returns an http.Handler that deletes records from cache
+// in response to POSTed Invalidation requests, so that upstream systems can
+// push invalidations. Requests must be signed with secret, see
+// SignatureHeader. It returns an error wrapping ErrInvalidConfig if secret is
+// empty, as anyone could then sign requests.
+func InvalidationHandler[K comparable, V any](cache *Cache[K, V], secret []byte) (http.Handler, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("%w: empty invalidation secret", ErrInvalidConfig)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxInvalidationBodySize))
+		if err != nil {
+			http.Error(w, "unable to read body", http.StatusBadRequest)
+			return
+		}
+
+		if !validSignature(secret, body, r.Header.Get(SignatureHeader)) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		var invalidation Invalidation[K]
+		if err := json.Unmarshal(body, &invalidation); err != nil {
+			http.Error(w, "invalid body", http.StatusBadRequest)
+			return
+		}
+
+		var zero K
+		if _, ok := any(zero).(string); !ok && len(invalidation.Prefixes) > 0 {
+			http.Error(w, "prefixes require string keys", http.StatusBadRequest)
+			return
+		}
+
+		n := cache.invalidate(invalidation)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(invalidationResponse{Invalidated: n})
+	}), nil
+}
+
+func validSignature(secret, body []byte, signature string) bool {
+	signature, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(actual, mac.Sum(nil))
+}
+
+// invalidate deletes the records matched by invalidation, and returns the
+// number of records deleted.
+func (cache *Cache[K, V]) invalidate(invalidation Invalidation[K]) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	keys := map[K]struct{}{}
+	for _, key := range invalidation.Keys {
+		if _, ok := cache.store[key]; ok {
+			keys[key] = struct{}{}
+		}
+	}
+	if len(invalidation.Prefixes) > 0 {
+		for key := range cache.store {
+			s, _ := any(key).(string)
+			for _, prefix := range invalidation.Prefixes {
+				if strings.HasPrefix(s, prefix) {
+					keys[key] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for key := range keys {
+		cache.deleteLocked(key, ReasonInvalidated)
+	}
+	return len(keys)
+}
@@ -0,0 +1,105 @@
+package cachemem
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var webhookSecret = []byte("secret")
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, webhookSecret)
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func invalidationRequest(body, signature string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/invalidate", strings.NewReader(body))
+	r.Header.Set(SignatureHeader, signature)
+	return r
+}
+
+func TestInvalidationHandler(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	cache.Set("2", time.Hour)
+	handler, _ := InvalidationHandler(cache, webhookSecret)
+
+	body := `{"keys": [1, 3]}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, invalidationRequest(body, sign(body)))
+
+	_, ok1 := cache.Get(1)
+	_, ok2 := cache.Get(2)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"invalidated": 1}`, w.Body.String())
+	assert.False(t, ok1)
+	assert.True(t, ok2)
+}
+
+func TestInvalidationHandler_prefixes(t *testing.T) {
+	cache, _ := New[string, string](nil, pathKey)
+	cache.Set("user:1:profile", time.Hour)
+	cache.Set("user:1:settings", time.Hour)
+	cache.Set("user:2:profile", time.Hour)
+	handler, _ := InvalidationHandler(cache, webhookSecret)
+
+	body := `{"prefixes": ["user:1:"]}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, invalidationRequest(body, sign(body)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestInvalidationHandler_prefixesNonStringKeys(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	handler, _ := InvalidationHandler(cache, webhookSecret)
+
+	body := `{"prefixes": ["1"]}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, invalidationRequest(body, sign(body)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestInvalidationHandler_invalidSignature(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	cache.Set("1", time.Hour)
+	handler, _ := InvalidationHandler(cache, webhookSecret)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, invalidationRequest(`{"keys": [1]}`, sign(`{"keys": [2]}`)))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestInvalidationHandler_method(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+	handler, _ := InvalidationHandler(cache, webhookSecret)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/invalidate", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestInvalidationHandler_emptySecret(t *testing.T) {
+	cache, _ := New[int, string](&testFetcher, getKey)
+
+	_, err := InvalidationHandler(cache, nil)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = InvalidationHandler(cache, []byte{})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
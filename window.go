@@ -0,0 +1,145 @@
+package cachemem
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type windowKey[K comparable] struct {
+	key    K
+	bucket int64
+}
+
+// WindowCache caches one value per key and fixed-size time window, such as
+// per-minute or per-hour aggregates. Windows roll over automatically as time
+// passes, and each window's values are kept for a fixed number of windows.
+type WindowCache[K comparable, V any] struct {
+	cache     Cache[windowKey[K], V]
+	window    time.Duration
+	retention int
+}
+
+// NewWindow initializes a new, empty WindowCache with windows of size window.
+// Values are retained for retention windows, including the window they
+// belong to. It returns an error wrapping ErrInvalidConfig if window or
+// retention is not positive, or cleanFreq is negative.
+func NewWindow[K comparable, V any](window time.Duration, retention int, cleanFreq time.Duration) (*WindowCache[K, V], error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("%w: non-positive window", ErrInvalidConfig)
+	}
+	if retention <= 0 {
+		return nil, fmt.Errorf("%w: non-positive retention", ErrInvalidConfig)
+	}
+
+	cfg := Config[windowKey[K], V]{CleanFrequency: cleanFreq}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	cache := &WindowCache[K, V]{
+		window:    window,
+		retention: retention,
+	}
+	cache.cache.init(nil, nil, cfg)
+	return cache, nil
+}
+
+func (cache *WindowCache[K, V]) bucket(at time.Time) int64 {
+	return at.UnixNano() / int64(cache.window)
+}
+
+// WindowStart returns the start of the window containing at.
+func (cache *WindowCache[K, V]) WindowStart(at time.Time) time.Time {
+	return time.Unix(0, cache.bucket(at)*int64(cache.window))
+}
+
+// Set writes value for key in the current window.
+func (cache *WindowCache[K, V]) Set(key K, value V) {
+	cache.SetAt(key, time.Now(), value)
+}
+
+// SetAt writes value for key in the window containing at. Values for windows
+// that are no longer retained are discarded.
+func (cache *WindowCache[K, V]) SetAt(key K, at time.Time, value V) {
+	bucket := cache.bucket(at)
+	e := entry[V]{
+		value:     value,
+		expiresAt: cache.cache.deadline(time.Unix(0, (bucket+int64(cache.retention))*int64(cache.window))),
+	}
+	if e.hasExpired(cache.cache.now()) {
+		return
+	}
+
+	cache.cache.mutex.Lock()
+	defer cache.cache.mutex.Unlock()
+	cache.cache.setLocked(windowKey[K]{key: key, bucket: bucket}, e, nil, "")
+}
+
+// Get retrieves the value for key in the current window.
+func (cache *WindowCache[K, V]) Get(key K) (V, bool) {
+	return cache.GetAt(key, time.Now())
+}
+
+// GetAt retrieves the value for key in the window containing at, if that
+// window is still retained.
+func (cache *WindowCache[K, V]) GetAt(key K, at time.Time) (V, bool) {
+	return cache.cache.Get(windowKey[K]{key: key, bucket: cache.bucket(at)})
+}
+
+// GetRecent retrieves the retained values for key, most recent window first.
+// Windows without a value are skipped.
+func (cache *WindowCache[K, V]) GetRecent(key K) []V {
+	var values []V
+
+	current := cache.bucket(time.Now())
+	for i := 0; i < cache.retention; i++ {
+		value, ok := cache.cache.Get(windowKey[K]{key: key, bucket: current - int64(i)})
+		if ok {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// Len returns the number of values in the cache across all windows,
+// including expired values.
+func (cache *WindowCache[K, V]) Len() int {
+	return cache.cache.Len()
+}
+
+// Clear deletes all values in the cache.
+func (cache *WindowCache[K, V]) Clear() {
+	cache.cache.Clear()
+}
+
+// StartCleaning begins removing expired windows from the cache at the
+// configured frequency. It blocks until StopCleaning is called.
+func (cache *WindowCache[K, V]) StartCleaning() {
+	cache.cache.StartCleaning()
+}
+
+// StartCleaningContext is like StartCleaning, but also stops cleaning when
+// ctx is done.
+func (cache *WindowCache[K, V]) StartCleaningContext(ctx context.Context) {
+	cache.cache.StartCleaningContext(ctx)
+}
+
+// StopCleaningContext is like StopCleaning, but waits for the cleaner to
+// stop, returning ctx.Err() if ctx is done first.
+func (cache *WindowCache[K, V]) StopCleaningContext(ctx context.Context) error {
+	return cache.cache.StopCleaningContext(ctx)
+}
+
+// StopCleaning stops removing expired windows from the cache.
+func (cache *WindowCache[K, V]) StopCleaning() {
+	cache.cache.StopCleaning()
+}
+
+// Close stops the cleaner and releases the values held by the cache. After
+// Close, writes are dropped and reads miss. Close returns ErrClosed if the
+// cache is already closed.
+func (cache *WindowCache[K, V]) Close() error {
+	return cache.cache.Close()
+}
@@ -0,0 +1,85 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowCache_Get(t *testing.T) {
+	cache, _ := NewWindow[string, int](time.Hour, 2, time.Second)
+	cache.Set("requests", 10)
+
+	actual, ok := cache.Get("requests")
+	assert.Equal(t, 10, actual)
+	assert.True(t, ok)
+}
+
+func TestWindowCache_GetAt(t *testing.T) {
+	cache, _ := NewWindow[string, int](time.Hour, 3, time.Second)
+	now := time.Now()
+	cache.SetAt("requests", now.Add(-time.Hour), 5)
+	cache.SetAt("requests", now, 10)
+
+	previous, okPrevious := cache.GetAt("requests", now.Add(-time.Hour))
+	current, okCurrent := cache.Get("requests")
+
+	assert.Equal(t, 5, previous)
+	assert.True(t, okPrevious)
+	assert.Equal(t, 10, current)
+	assert.True(t, okCurrent)
+}
+
+func TestWindowCache_SetAt_notRetained(t *testing.T) {
+	cache, _ := NewWindow[string, int](time.Hour, 2, time.Second)
+	cache.SetAt("requests", time.Now().Add(-3*time.Hour), 5)
+
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestWindowCache_GetRecent(t *testing.T) {
+	cache, _ := NewWindow[string, int](time.Hour, 3, time.Second)
+	now := time.Now()
+	cache.SetAt("requests", now.Add(-2*time.Hour), 1)
+	cache.SetAt("requests", now, 3)
+
+	actual := cache.GetRecent("requests")
+	assert.Equal(t, []int{3, 1}, actual)
+}
+
+func TestWindowCache_WindowStart(t *testing.T) {
+	cache, _ := NewWindow[string, int](time.Minute, 1, time.Second)
+	at := time.Date(2024, 1, 1, 10, 30, 45, 0, time.UTC)
+
+	actual := cache.WindowStart(at)
+	assert.True(t, actual.Equal(time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)))
+}
+
+func TestNewWindow_invalid(t *testing.T) {
+	_, err := NewWindow[string, int](0, 2, time.Second)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = NewWindow[string, int](time.Hour, 0, time.Second)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = NewWindow[string, int](time.Hour, 2, -time.Second)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestWindowCache_Close(t *testing.T) {
+	cache, _ := NewWindow[string, int](time.Hour, 2, time.Millisecond)
+	cache.Set("requests", 10)
+	done := make(chan struct{})
+	go func() {
+		cache.StartCleaning()
+		close(done)
+	}()
+	time.Sleep(2 * time.Millisecond)
+
+	assert.NoError(t, cache.Close())
+	<-done
+	_, ok := cache.Get("requests")
+	assert.False(t, ok)
+	assert.ErrorIs(t, cache.Close(), ErrClosed)
+}
@@ -0,0 +1,56 @@
+package cachemem
+
+import "reflect"
+
+// ZeroValuePolicy decides what the cache does when the fetcher returns the
+// zero value of V with a nil error, which some backends use to signal that a
+// record does not exist.
+type ZeroValuePolicy int
+
+const (
+	// ZeroValueCache caches zero values like any other record. It is the
+	// default.
+	ZeroValueCache ZeroValuePolicy = iota
+	// ZeroValueMissing treats zero values as missing records: GetOrFetch
+	// returns ErrNotFound, which is cached if the cache was initialized
+	// WithNegativeTTL or WithNegativeTTLFunc.
+	ZeroValueMissing
+	// ZeroValueReject returns ErrZeroValue from GetOrFetch without caching
+	// anything.
+	ZeroValueReject
+)
+
+// isZero reports whether value is the zero value of V.
+func isZero[V any](value V) bool {
+	return reflect.ValueOf(&value).Elem().IsZero()
+}
+
+// setZero handles a zero value fetched for key according to the cache's zero
+// value policy.
+func (cache *Cache[K, V]) setZero(key K) (V, error) {
+	var v V
+	if cache.zeroValue == ZeroValueReject {
+		return v, ErrZeroValue
+	}
+
+	cache.setError(key, ErrNotFound)
+	return v, ErrNotFound
+}
+
+// dropZeros returns values and their keys without zero values, unless zero
+// values are cached.
+func (cache *Cache[K, V]) dropZeros(keys []K, values []V) ([]K, []V) {
+	if cache.zeroValue == ZeroValueCache {
+		return keys, values
+	}
+
+	var nonZeroKeys []K
+	var nonZero []V
+	for i, value := range values {
+		if !isZero(value) {
+			nonZeroKeys = append(nonZeroKeys, keys[i])
+			nonZero = append(nonZero, value)
+		}
+	}
+	return nonZeroKeys, nonZero
+}
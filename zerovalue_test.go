@@ -0,0 +1,55 @@
+package cachemem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type zeroFetcher struct{}
+
+func (zeroFetcher) FetchOne(int) (string, error) {
+	return "", nil
+}
+
+func (zeroFetcher) FetchMany(arrI []int) ([]string, error) {
+	return make([]string, len(arrI)), nil
+}
+
+func TestCache_GetOrFetch_zeroValue(t *testing.T) {
+	cache, _ := New[int, string](zeroFetcher{}, getKey)
+
+	actual, err := cache.GetOrFetch(0, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", actual)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestCache_GetOrFetch_zeroValueMissing(t *testing.T) {
+	cache, _ := New[int, string](
+		zeroFetcher{},
+		getKey,
+		WithZeroValue[int, string](ZeroValueMissing),
+		WithNegativeTTL[int, string](time.Hour),
+	)
+
+	_, err := cache.GetOrFetch(0, time.Hour)
+	_, cachedErr := cache.GetE(0)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, cachedErr, ErrNotFound)
+	assert.Equal(t, uint64(1), cache.Stats().NegativeHits)
+}
+
+func TestCache_GetOrFetch_zeroValueReject(t *testing.T) {
+	cache, _ := New[int, string](zeroFetcher{}, getKey, WithZeroValue[int, string](ZeroValueReject))
+
+	_, err := cache.GetOrFetch(0, time.Hour)
+	fetchManyErr := cache.FetchMany([]int{1, 2}, time.Hour)
+
+	assert.ErrorIs(t, err, ErrZeroValue)
+	assert.NoError(t, fetchManyErr)
+	assert.Equal(t, 0, cache.Len())
+}